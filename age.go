@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+const OLDEST_LINES = 10
+
+// AgeBucket groups the matched lines last changed at most MaxAge ago.
+type AgeBucket struct {
+	Label  string
+	MaxAge time.Duration
+}
+
+// UNCOMMITTED_LABEL is the bucket of the lines not committed yet, in untracked files or
+// changed since the last commit.
+const UNCOMMITTED_LABEL = "uncommitted"
+
+// AGE_BUCKETS go from the lines of the current sprint to the long-standing ones.
+var AGE_BUCKETS = []AgeBucket{
+	{"this sprint (2 weeks)", 14 * 24 * time.Hour},
+	{"last 3 months", 91 * 24 * time.Hour},
+	{"last year", 365 * 24 * time.Hour},
+	{"last 3 years", 3 * 365 * 24 * time.Hour},
+	{"older", 0},
+}
+
+// ageBucket returns the index in AGE_BUCKETS of a line last changed age ago.
+func ageBucket(age time.Duration) int {
+	for i, bucket := range AGE_BUCKETS {
+		if bucket.MaxAge == 0 || age <= bucket.MaxAge {
+			return i
+		}
+	}
+	return len(AGE_BUCKETS) - 1
+}
+
+// ageReport tells how long the matched lines have existed, bucketed by the date of the
+// commit that last changed them, and lists the oldest ones.
+func (r Report) ageReport() (string, error) {
+	blamed := blameFindings(r.Findings)
+	type agedLine struct {
+		finding Match
+		blame   Blame
+	}
+	counts := make([]int, len(AGE_BUCKETS))
+	uncommitted := 0
+	aged := []agedLine{}
+	for _, finding := range r.Findings {
+		blames, ok := blamed[finding.Path]
+		if !ok {
+			continue
+		}
+		blame, ok := blames[finding.Line]
+		if !ok || strings.HasPrefix(blame.Commit, "0000000") {
+			uncommitted++
+			continue
+		}
+		counts[ageBucket(r.ScannedAt.Sub(blame.Time))]++
+		aged = append(aged, agedLine{finding: finding, blame: blame})
+	}
+	total := uncommitted + len(aged)
+	if total == 0 {
+		return "No matched lines with git history.\n", nil
+	}
+
+	share := func(label string, lines int) []string {
+		percent := 100 * float64(lines) / float64(total)
+		return []string{label, strconv.Itoa(lines), fmt.Sprintf("%5.1f%%", percent), coverageBar(percent, COVERAGE_BAR_WIDTH)}
+	}
+	data := pterm.TableData{{"Last changed", "Lines", "Share", ""}, share(UNCOMMITTED_LABEL, uncommitted)}
+	for i, bucket := range AGE_BUCKETS {
+		data = append(data, share(bucket.Label, counts[i]))
+	}
+	buckets, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return "", fmt.Errorf("error rendering ages: %v", err)
+	}
+	if len(aged) == 0 {
+		return buckets + "\n", nil
+	}
+
+	sort.SliceStable(aged, func(i, j int) bool { return aged[i].blame.Time.Before(aged[j].blame.Time) })
+	if len(aged) > OLDEST_LINES {
+		aged = aged[:OLDEST_LINES]
+	}
+	data = pterm.TableData{{"Oldest lines", "Rule", "Since", "Author"}}
+	for _, line := range aged {
+		data = append(data, []string{
+			workspacePath(line.finding.Path) + ":" + strconv.Itoa(line.finding.Line),
+			line.finding.Rule,
+			line.blame.Time.Format("2006-01-02"),
+			line.blame.Author,
+		})
+	}
+	oldest, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return "", fmt.Errorf("error rendering ages: %v", err)
+	}
+	return buckets + "\n\n" + oldest + "\n", nil
+}
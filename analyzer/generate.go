@@ -0,0 +1,5 @@
+// Package analyzer holds the gRPC protocol spoken between dirwalker and
+// external analyzer processes.
+package analyzer
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative analyzer.proto
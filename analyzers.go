@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gaganj/dirwalker/analyzer"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const ANALYZER_HANDSHAKE_TIMEOUT = 10 * time.Second
+const ANALYZER_SCAN_TIMEOUT = 30 * time.Second
+
+var analyzers = []*ExternalAnalyzer{}
+
+// ExternalAnalyzer is a long-running process speaking the analyzer gRPC protocol.
+// On startup the process must print the address it listens on (e.g. "127.0.0.1:50051"
+// or "unix:///tmp/analyzer.sock") as the first line of its standard output.
+type ExternalAnalyzer struct {
+	command string
+	cmd     *exec.Cmd
+	conn    *grpc.ClientConn
+	client  analyzer.AnalyzerClient
+}
+
+// stringList collects repeated command line flags.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func startAnalyzer(command string) (*ExternalAnalyzer, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty analyzer command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error starting analyzer %s: %v", command, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting analyzer %s: %v", command, err)
+	}
+
+	address := make(chan string, 1)
+	go func() {
+		reader := bufio.NewReader(stdout)
+		line, _ := reader.ReadString('\n')
+		address <- strings.TrimSpace(line)
+		// keep draining so a chatty analyzer never blocks on a full pipe
+		for {
+			if _, err := reader.ReadString('\n'); err != nil {
+				return
+			}
+		}
+	}()
+
+	var target string
+	select {
+	case target = <-address:
+	case <-time.After(ANALYZER_HANDSHAKE_TIMEOUT):
+	}
+	if target == "" {
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("analyzer %s did not report its address", command)
+	}
+
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("error connecting to analyzer %s: %v", command, err)
+	}
+	logger.Info().Msg("🔌 Connected to analyzer " + command + " at " + target)
+
+	return &ExternalAnalyzer{
+		command: command,
+		cmd:     cmd,
+		conn:    conn,
+		client:  analyzer.NewAnalyzerClient(conn),
+	}, nil
+}
+
+func (a *ExternalAnalyzer) scanFile(filePath string, content []byte) ([]*analyzer.Match, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ANALYZER_SCAN_TIMEOUT)
+	defer cancel()
+
+	resp, err := a.client.ScanFile(ctx, &analyzer.ScanFileRequest{Path: filePath, Content: content})
+	if err != nil {
+		return nil, fmt.Errorf("analyzer %s failed on %s: %v", a.command, filePath, err)
+	}
+	return resp.GetMatches(), nil
+}
+
+func (a *ExternalAnalyzer) stop() {
+	a.conn.Close()
+	a.cmd.Process.Kill()
+	a.cmd.Wait()
+}
+
+func startAnalyzers(commands []string) error {
+	for _, command := range commands {
+		a, err := startAnalyzer(command)
+		if err != nil {
+			logger.Error().Msg(err.Error())
+			stopAnalyzers()
+			return err
+		}
+		analyzers = append(analyzers, a)
+	}
+	return nil
+}
+
+func stopAnalyzers() {
+	for _, a := range analyzers {
+		a.stop()
+	}
+	analyzers = []*ExternalAnalyzer{}
+}
+
+// runAnalyzers sends the file to every external analyzer and returns how many matches they found,
+// stopping once limit is reached (0 means no limit). onMatch, when set, is called for every match.
+// The errors go to the log of the scan.
+func runAnalyzers(filePath string, content []byte, limit int, onMatch func(match Match), log zerolog.Logger) int {
+	count := 0
+	for _, a := range analyzers {
+		matches, err := a.scanFile(filePath, content)
+		if err != nil {
+			// a misbehaving analyzer shouldn't abort the whole walk
+			log.Error().Msg(err.Error())
+			continue
+		}
+		for _, match := range matches {
+			if onMatch != nil {
+				onMatch(Match{Line: int(match.GetLine()), Col: int(match.GetColumn()), Rule: match.GetRule(), Snippet: match.GetSnippet(), Key: match.GetKey(), analyzer: a.command})
+			}
+			count++
+			if limit > 0 && count >= limit {
+				return count
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sortFindings orders findings by path, in the -sort order, then by line.
+func sortFindings(findings []Match, order string) []Match {
+	paths := []string{}
+	rank := map[string]int{}
+	for _, finding := range findings {
+		if _, ok := rank[finding.Path]; !ok {
+			rank[finding.Path] = len(paths)
+			paths = append(paths, finding.Path)
+		}
+	}
+	sortPaths(paths, order)
+	for i, p := range paths {
+		rank[p] = i
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return rank[findings[i].Path] < rank[findings[j].Path]
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// githubAnnotations renders the report as GitHub Actions workflow commands, so findings
+// show inline on the pull request: a warning per matched line and an error per
+// unreadable file. Paths are made relative to the working directory, the checkout.
+func (r Report) githubAnnotations() string {
+	var out strings.Builder
+	for _, finding := range r.Findings {
+		fmt.Fprintf(&out, "::warning file=%s,line=%d,col=%d,title=%s::%s\n",
+			githubProperty(workspacePath(finding.Path)), finding.Line, finding.Col,
+			githubProperty("dirwalker "+finding.Rule), githubData("Translation content matched by rule "+finding.Rule))
+	}
+	for _, failed := range r.FailedFiles {
+		fmt.Fprintf(&out, "::error file=%s,title=dirwalker::%s\n", githubProperty(workspacePath(failed.Path)), githubData(failed.Error))
+	}
+	fmt.Fprintf(&out, "%d files found with translation content (%d scanned).\n", len(r.FoundFiles), r.FilesScanned)
+	return out.String()
+}
+
+func workspacePath(filePath string) string {
+	if !filepath.IsAbs(filePath) {
+		return filepath.ToSlash(filepath.Clean(filePath))
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, filePath); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(filePath)
+}
+
+// githubData and githubProperty escape the message and the parameters of a workflow command.
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const CATALOG_ARB = "arb"
+
+const PROBLEM_PLACEHOLDER = "placeholder"
+
+// messagePlaceholders lists the distinct argument names of an ICU message, sorted:
+// {name}, {count, plural, =0{none} other{{count} items}}. The text of plural and select
+// branches is read as a message itself so a branch like =0{Aucun} isn't an argument.
+func messagePlaceholders(text string) []string {
+	seen := map[string]bool{}
+	parseICUMessage(text, 0, seen)
+	names := []string{}
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseICUMessage reads message text from i to the closing brace of its enclosing branch,
+// or the end, returning the position after it.
+func parseICUMessage(text string, i int, seen map[string]bool) int {
+	for i < len(text) {
+		switch text[i] {
+		case '}':
+			return i + 1
+		case '{':
+			i = parseICUArgument(text, i+1, seen)
+		default:
+			i++
+		}
+	}
+	return i
+}
+
+// parseICUArgument reads an argument from just after its opening brace.
+func parseICUArgument(text string, i int, seen map[string]bool) int {
+	end := strings.IndexAny(text[i:], ",}")
+	if end < 0 {
+		return len(text)
+	}
+	if name := strings.TrimSpace(text[i : i+end]); name != "" {
+		seen[name] = true
+	}
+	i += end
+	if text[i] == '}' {
+		return i + 1
+	}
+	i++
+	end = strings.IndexAny(text[i:], ",}")
+	if end < 0 {
+		return len(text)
+	}
+	kind := strings.TrimSpace(text[i : i+end])
+	i += end
+	if text[i] == '}' {
+		return i + 1
+	}
+	i++
+	if kind != "plural" && kind != "select" && kind != "selectordinal" {
+		// a style, like {amount, number, currency}: skip to the matching brace
+		for depth := 1; i < len(text); i++ {
+			switch text[i] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return i
+	}
+	// branches: selector{message} ... up to the closing brace of the argument
+	for i < len(text) {
+		switch text[i] {
+		case '}':
+			return i + 1
+		case '{':
+			i = parseICUMessage(text, i+1, seen)
+		default:
+			i++
+		}
+	}
+	return i
+}
+
+// parseARB reads a Flutter Application Resource Bundle: a flat JSON object of messages
+// where "@key" holds the metadata of key (its description and placeholders) and "@@locale"
+// the locale of the file, overriding the one in its name.
+func (c *Catalog) parseARB(contents []byte) error {
+	flat := newCatalog(c.Path, CATALOG_JSON)
+	if err := flat.parseJSON(contents); err != nil {
+		return err
+	}
+	// the raw values are kept to write the metadata back as it was
+	if err := json.Unmarshal(contents, &c.raw); err != nil {
+		return err
+	}
+	c.Duplicates = flat.Duplicates
+	for _, key := range flat.Keys {
+		message := flat.Messages[key]
+		switch {
+		case key == "@@locale":
+			c.Locale = message.Text
+			c.attributes = append(c.attributes, key)
+		case strings.HasPrefix(key, "@@"):
+			// other global attributes, like @@last_modified
+			if name := strings.SplitN(key, ".", 2)[0]; !contains(c.attributes, name) {
+				c.attributes = append(c.attributes, name)
+			}
+		case strings.HasPrefix(key, "@"):
+			parts := strings.SplitN(key[1:], ".", 4)
+			if len(parts) < 2 {
+				continue
+			}
+			owner := c.Metadata(parts[0])
+			switch {
+			case parts[1] == "description":
+				owner.Description = message.Text
+			case parts[1] == "placeholders" && len(parts) >= 3:
+				if !contains(owner.Placeholders, parts[2]) {
+					owner.Placeholders = append(owner.Placeholders, parts[2])
+				}
+			}
+		default:
+			c.add(message)
+		}
+	}
+	// metadata may come before or after its message
+	for key, metadata := range c.metadata {
+		if message, ok := c.Messages[key]; ok {
+			message.Description = metadata.Description
+			message.Placeholders = metadata.Placeholders
+			message.metadata = c.raw["@"+key]
+			c.Messages[key] = message
+		}
+	}
+	return nil
+}
+
+// Metadata returns the ARB metadata collected for key so far.
+func (c *Catalog) Metadata(key string) *Message {
+	if c.metadata == nil {
+		c.metadata = map[string]*Message{}
+	}
+	if _, ok := c.metadata[key]; !ok {
+		c.metadata[key] = &Message{Key: key}
+	}
+	return c.metadata[key]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPlaceholders reports the placeholders of an ARB source message that its metadata
+// doesn't declare, and the translations whose placeholders differ from the source message.
+func checkPlaceholders(source *Catalog, c *Catalog) []CatalogProblem {
+	problems := []CatalogProblem{}
+	if source.Format != CATALOG_ARB {
+		return problems
+	}
+	for _, key := range source.Keys {
+		message := source.Messages[key]
+		used := messagePlaceholders(message.Text)
+		if c == source {
+			for _, name := range used {
+				if !contains(message.Placeholders, name) {
+					problems = append(problems, CatalogProblem{Path: c.Path, Line: message.Line, Kind: PROBLEM_PLACEHOLDER, Key: key, Message: "uses {" + name + "} without declaring it in @" + key})
+				}
+			}
+			continue
+		}
+		translation, ok := c.Messages[key]
+		if !ok || translation.Text == "" {
+			continue
+		}
+		if translated := messagePlaceholders(translation.Text); strings.Join(translated, ",") != strings.Join(used, ",") {
+			problems = append(problems, CatalogProblem{Path: c.Path, Line: translation.Line, Kind: PROBLEM_PLACEHOLDER, Key: key, Message: "uses " + placeholderList(translated) + " instead of " + placeholderList(used)})
+		}
+	}
+	return problems
+}
+
+func placeholderList(names []string) string {
+	if len(names) == 0 {
+		return "no placeholders"
+	}
+	return "{" + strings.Join(names, "}, {") + "}"
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// ARCHIVE_SEPARATOR joins an archive's path and the path of an entry inside it.
+const ARCHIVE_SEPARATOR = "!/"
+
+// MAX_ARCHIVE_ENTRY_SIZE bounds how much of a single archive entry is read, so decompression bombs can't exhaust memory
+// or the time of the scan.
+const MAX_ARCHIVE_ENTRY_SIZE = 64 * 1024 * 1024
+
+func isArchive(filePath string) bool {
+	name := strings.ToLower(filePath)
+	return strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".tar") ||
+		strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// scanArchive matches the candidate files inside a zip or tar archive, reporting them as archive.zip!/inner/path.
+// Archives nested in archives are not descended into. A broken archive, or one whose reading
+// times out, is recorded as failed and skipped, the entries already read staying matched.
+func (s *Scanner) scanArchive(archivePath string) error {
+	s.log.Info().Msg("📦 Scanning archive " + archivePath)
+	// the reading given up on stops at the next entry
+	var abandoned atomic.Bool
+	err := s.withTimeout(func() error {
+		if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+			return s.scanZip(archivePath, &abandoned)
+		}
+		return s.scanTar(archivePath, &abandoned)
+	})
+	if err != nil {
+		abandoned.Store(true)
+		err = fmt.Errorf("error reading archive %s: %v", archivePath, err)
+		s.log.Error().Msg(err.Error())
+		s.recordFailure(archivePath, err)
+	}
+	return nil
+}
+
+func (s *Scanner) scanZip(archivePath string, abandoned *atomic.Bool) error {
+	f, err := s.fs.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	reader, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range reader.File {
+		// the rest of the archive isn't read once done
+		if abandoned.Load() || s.limitReached.Load() || s.expired() {
+			return nil
+		}
+		if f.FileInfo().IsDir() || !isCandidate(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = s.scanArchiveEntry(archivePath, f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scanner) scanTar(archivePath string, abandoned *atomic.Bool) error {
+	f, err := s.fs.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if !strings.HasSuffix(strings.ToLower(archivePath), ".tar") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	reader := tar.NewReader(r)
+	for {
+		if abandoned.Load() || s.limitReached.Load() || s.expired() {
+			return nil
+		}
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || !isCandidate(header.Name) {
+			continue
+		}
+		if err := s.scanArchiveEntry(archivePath, header.Name, reader); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Scanner) scanArchiveEntry(archivePath string, entryName string, r io.Reader) error {
+	limit := s.matchLimit()
+	if s.limitReached.Load() || s.expired() {
+		return nil
+	}
+	s.filesScanned.Add(1)
+	entryPath := archivePath + ARCHIVE_SEPARATOR + strings.TrimPrefix(entryName, "./")
+	limited := &io.LimitedReader{R: r, N: MAX_ARCHIVE_ENTRY_SIZE}
+	defer func() {
+		// all of the limit read, with more to come
+		if limited.N == 0 && entryTruncated(r) {
+			s.log.Warn().Msg(fmt.Sprintf("✂️ Matched the first %d MiB of archive entry %s only", MAX_ARCHIVE_ENTRY_SIZE/MIB, entryPath))
+		}
+	}()
+
+	// streamed under -max-memory like the files, the content handed to onContent being whole
+	if s.streamWindow > 0 && s.onContent == nil {
+		var entry indexEntry
+		matches, findings, err := s.streamReader(entryPath, limited, limit, &entry)
+		if err != nil {
+			return err
+		}
+		if entry.bom {
+			s.recordBOM(entryPath)
+		}
+		if entry.generated && !s.options.IncludeGenerated {
+			s.skipGenerated(entryPath)
+			return nil
+		}
+		if entry.ignored {
+			s.skipIgnored(entryPath)
+			return nil
+		}
+		s.record(entryPath, matches, findings)
+		return nil
+	}
+
+	content, err := io.ReadAll(limited)
+	if err != nil {
+		return err
+	}
+	s.bytesRead.Add(int64(len(content)))
+	s.throttle.wait(len(content))
+
+	content, bom := stripBOM(content)
+	if bom {
+		s.recordBOM(entryPath)
+	}
+	if !s.options.IncludeGenerated && isGenerated(content) {
+		s.skipGenerated(entryPath)
+		return nil
+	}
+	if isIgnored(content) {
+		s.skipIgnored(entryPath)
+		return nil
+	}
+	matches, findings := s.matchContent(entryPath, content, 1, limit)
+	if s.record(entryPath, matches, findings) && s.onContent != nil {
+		s.onContent(entryPath, content)
+	}
+	return nil
+}
+
+// entryTruncated tells whether an entry goes on past the MAX_ARCHIVE_ENTRY_SIZE read of it.
+func entryTruncated(r io.Reader) bool {
+	n, _ := io.ReadFull(r, make([]byte, 1))
+	return n > 0
+}
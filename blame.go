@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// Blame is who last changed a line and when, as git blame tells it.
+type Blame struct {
+	Author string
+	Email  string
+	Time   time.Time
+	Commit string
+}
+
+// blameFindings runs git blame on the matched lines of every file, by file then line.
+// It needs the scanned tree to be a local git checkout. The files git can't blame, like
+// untracked ones, are skipped with a warning and have no blames.
+func blameFindings(findings []Match) map[string]map[int]Blame {
+	lines := map[string][]int{}
+	for _, finding := range findings {
+		lines[finding.Path] = append(lines[finding.Path], finding.Line)
+	}
+	blamed := map[string]map[int]Blame{}
+	for filePath, numbers := range lines {
+		if isRemote(filePath) || strings.Contains(filePath, ARCHIVE_SEPARATOR) {
+			continue
+		}
+		blames, err := blameLines(filePath, numbers)
+		if err != nil {
+			logger.Warn().Msg("🙈 Leaving the matches of " + filePath + " unblamed: " + err.Error())
+		}
+		blamed[filePath] = blames
+	}
+	return blamed
+}
+
+// blameLines asks git blame about the given lines of a single file.
+func blameLines(filePath string, numbers []int) (map[int]Blame, error) {
+	args := []string{"-C", filepath.Dir(filePath), "blame", "--line-porcelain"}
+	for _, n := range numbers {
+		args = append(args, "-L", strconv.Itoa(n)+","+strconv.Itoa(n))
+	}
+	args = append(args, "--", filepath.Base(filePath))
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git blame on %s: %v %s", filePath, err, strings.TrimSpace(stderr.String()))
+	}
+
+	blames := map[int]Blame{}
+	var line int
+	var current Blame
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			// the line's content ends its block
+			blames[line] = current
+			current = Blame{}
+		case strings.HasPrefix(text, "author "):
+			current.Author = strings.TrimPrefix(text, "author ")
+		case strings.HasPrefix(text, "author-mail "):
+			current.Email = strings.Trim(strings.TrimPrefix(text, "author-mail "), "<>")
+		case strings.HasPrefix(text, "author-time "):
+			seconds, _ := strconv.ParseInt(strings.TrimPrefix(text, "author-time "), 10, 64)
+			current.Time = time.Unix(seconds, 0)
+		default:
+			// "<sha> <original line> <final line> [<group size>]" starts a block
+			if fields := strings.Fields(text); len(fields) >= 3 && len(fields[0]) == 40 {
+				current.Commit = fields[0]
+				line, _ = strconv.Atoi(fields[2])
+			}
+		}
+	}
+	return blames, nil
+}
+
+// authorsTable ranks the last authors of the matched lines by how many they own.
+func (r Report) authorsTable() (string, error) {
+	blamed := blameFindings(r.Findings)
+	type authorStats struct {
+		name  string
+		lines int
+		files map[string]bool
+		rules map[string]bool
+		last  time.Time
+	}
+	stats := map[string]*authorStats{}
+	for _, finding := range r.Findings {
+		blame, ok := blamed[finding.Path][finding.Line]
+		if !ok {
+			continue
+		}
+		name := blame.Author
+		if blame.Email != "" {
+			name += " <" + blame.Email + ">"
+		}
+		s, ok := stats[name]
+		if !ok {
+			s = &authorStats{name: name, files: map[string]bool{}, rules: map[string]bool{}}
+			stats[name] = s
+		}
+		s.lines++
+		s.files[finding.Path] = true
+		s.rules[finding.Rule] = true
+		if blame.Time.After(s.last) {
+			s.last = blame.Time
+		}
+	}
+	if len(stats) == 0 {
+		return "No matched lines with git history.\n", nil
+	}
+	ranked := []*authorStats{}
+	for _, s := range stats {
+		ranked = append(ranked, s)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].lines != ranked[j].lines {
+			return ranked[i].lines > ranked[j].lines
+		}
+		return ranked[i].name < ranked[j].name
+	})
+
+	data := pterm.TableData{{"#", "Author", "Lines", "Files", "Rules", "Latest change"}}
+	for i, s := range ranked {
+		rules := []string{}
+		for rule := range s.rules {
+			rules = append(rules, rule)
+		}
+		sort.Strings(rules)
+		data = append(data, []string{
+			strconv.Itoa(i + 1), s.name, strconv.Itoa(s.lines), strconv.Itoa(len(s.files)), strings.Join(rules, ", "), s.last.Format("2006-01-02"),
+		})
+	}
+	table, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return "", fmt.Errorf("error rendering authors: %v", err)
+	}
+	return table + "\n", nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Bookmark is a named scan target from the bookmarks section of the config.
+type Bookmark struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+// findBookmark returns the path of the named bookmark, with a leading ~ expanded.
+func findBookmark(bookmarks []Bookmark, name string) (string, error) {
+	for _, b := range bookmarks {
+		if b.Name == name {
+			return expandHome(b.Path), nil
+		}
+	}
+	names := []string{}
+	for _, b := range bookmarks {
+		names = append(names, b.Name)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("unknown bookmark %s, none are configured", name)
+	}
+	return "", fmt.Errorf("unknown bookmark %s, expected one of %s", name, strings.Join(names, ", "))
+}
+
+func expandHome(location string) string {
+	if location != "~" && !strings.HasPrefix(location, "~/") {
+		return location
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return location
+	}
+	return filepath.Join(home, strings.TrimPrefix(location, "~"))
+}
+
+// updateBookmarks handles messages while the bookmark menu is shown.
+func (m Model) updateBookmarks(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case key.Matches(keyMsg, keys.Quit):
+		return m, tea.Quit
+	case key.Matches(keyMsg, keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	case key.Matches(keyMsg, keys.Log):
+		m.showLog = !m.showLog
+	case key.Matches(keyMsg, keys.MenuUp):
+		if m.bookmarkIndex > 0 {
+			m.bookmarkIndex--
+		}
+	case key.Matches(keyMsg, keys.MenuDown):
+		if m.bookmarkIndex < len(config.Bookmarks)-1 {
+			m.bookmarkIndex++
+		}
+	case key.Matches(keyMsg, keys.MenuChoose):
+		m.choosing = false
+		dirPath := expandHome(config.Bookmarks[m.bookmarkIndex].Path)
+		if err := validateLocation(dirPath); err != nil {
+			// back to the prompt so the path can be fixed
+			m.textInput.SetValue(dirPath)
+			m.textInput.CursorEnd()
+			m.inputErr = err
+			return m, textinput.Blink
+		}
+		return m.beginScan(dirPath)
+	case key.Matches(keyMsg, keys.MenuCancel):
+		m.choosing = false
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+func (m Model) bookmarksView() string {
+	view := "Choose a bookmark to scan :\n"
+	for i, b := range config.Bookmarks {
+		cursor := "  "
+		if i == m.bookmarkIndex {
+			cursor = "> "
+		}
+		view += cursor + b.Name + " (" + b.Path + ")\n"
+	}
+	return view
+}
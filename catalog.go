@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const CATALOG_JSON = "json"
+const CATALOG_YAML = "yaml"
+
+const DEFAULT_SOURCE_LOCALE = "en"
+
+// Message is one entry of a catalog, its key flattened with dots ("home.title").
+type Message struct {
+	Key  string
+	Text string
+	Line int
+	// Description and Placeholders come from the comments or metadata of the formats having them.
+	Description  string
+	Placeholders []string
+
+	// literal is how a value that isn't a string is written back: a number, true, null.
+	literal string
+	// metadata is the raw "@key" object of an ARB message.
+	metadata json.RawMessage
+	// origin is the file:line a message was extracted from.
+	origin string
+}
+
+// Catalog holds the messages of one locale file.
+type Catalog struct {
+	Path   string
+	Locale string
+	Format string
+	// Keys are in the order of the file.
+	Keys     []string
+	Messages map[string]Message
+	// Duplicates are the keys defined more than once, the last definition winning.
+	Duplicates []Message
+
+	metadata map[string]*Message
+	// nested is set when the file nests its keys rather than writing them with dots,
+	// localeRoot when a YAML file has them under a top-level locale key.
+	nested     bool
+	localeRoot bool
+	// header is the comment at the top of the file, kept when writing it back.
+	header string
+	// bom is set when the file started with a byte order mark, dropped when writing it back.
+	bom bool
+	// ascii is set for a .properties file to be written back with \uXXXX escapes.
+	ascii bool
+	// attributes are the "@@" entries of an ARB file, in order.
+	attributes []string
+	raw        map[string]json.RawMessage
+}
+
+func newCatalog(catalogPath string, format string) *Catalog {
+	locale := catalogLocale(catalogPath)
+	if format == CATALOG_PROPERTIES || format == CATALOG_ARB {
+		locale = propertiesLocale(catalogPath)
+	}
+	return &Catalog{Path: catalogPath, Locale: locale, Format: format, Messages: map[string]Message{}}
+}
+
+func (c *Catalog) add(message Message) {
+	if _, ok := c.Messages[message.Key]; ok {
+		c.Duplicates = append(c.Duplicates, message)
+	} else {
+		c.Keys = append(c.Keys, message.Key)
+	}
+	c.Messages[message.Key] = message
+}
+
+// catalogFormat tells the format of a catalog file from its extension, "" when it isn't one.
+func catalogFormat(catalogPath string) string {
+	switch strings.ToLower(filepath.Ext(catalogPath)) {
+	case ".json":
+		return CATALOG_JSON
+	case ".yaml", ".yml":
+		return CATALOG_YAML
+	case ".properties":
+		return CATALOG_PROPERTIES
+	case ".arb":
+		return CATALOG_ARB
+	}
+	return ""
+}
+
+// catalogLocale is the locale a file holds, from its name: fr.json, messages.fr.yaml.
+func catalogLocale(catalogPath string) string {
+	name := strings.TrimSuffix(filepath.Base(catalogPath), filepath.Ext(catalogPath))
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// loadCatalog reads a catalog file in any of the supported formats.
+func loadCatalog(catalogPath string) (*Catalog, error) {
+	contents, err := os.ReadFile(catalogPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading catalog %s: %v", catalogPath, err)
+	}
+	format := catalogFormat(catalogPath)
+	c := newCatalog(catalogPath, format)
+	contents, c.bom = stripBOM(contents)
+	switch format {
+	case CATALOG_JSON:
+		err = c.parseJSON(contents)
+	case CATALOG_YAML:
+		err = c.parseYAML(contents)
+	case CATALOG_PROPERTIES:
+		err = c.parseProperties(contents)
+	case CATALOG_ARB:
+		err = c.parseARB(contents)
+	default:
+		err = fmt.Errorf("unknown format")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing catalog %s: %v", catalogPath, err)
+	}
+	return c, nil
+}
+
+// parseJSON flattens nested objects into dotted keys. The token stream is walked rather
+// than decoded into a map so duplicate keys can be reported.
+func (c *Catalog) parseJSON(contents []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	lineAt := func(offset int64) int {
+		return bytes.Count(contents[:offset], []byte("\n")) + 1
+	}
+	var walk func(prefix string) error
+	walk = func(prefix string) error {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				if prefix != "" {
+					c.nested = true
+				}
+				for decoder.More() {
+					keyToken, err := decoder.Token()
+					if err != nil {
+						return err
+					}
+					if err := walk(joinKey(prefix, keyToken.(string))); err != nil {
+						return err
+					}
+				}
+			case '[':
+				c.nested = true
+				for i := 0; decoder.More(); i++ {
+					if err := walk(joinKey(prefix, strconv.Itoa(i))); err != nil {
+						return err
+					}
+				}
+			}
+			// the closing delimiter
+			_, err := decoder.Token()
+			return err
+		case string:
+			c.add(Message{Key: prefix, Text: t, Line: lineAt(offset)})
+		case nil:
+			c.add(Message{Key: prefix, Line: lineAt(offset), literal: "null"})
+		default:
+			c.add(Message{Key: prefix, Text: fmt.Sprint(t), Line: lineAt(offset), literal: fmt.Sprint(t)})
+		}
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected content after the top-level value")
+	}
+	return nil
+}
+
+// parseYAML flattens nested mappings into dotted keys. A Rails style file, holding a
+// single top-level key named after its locale ("fr:"), has that level dropped.
+func (c *Catalog) parseYAML(contents []byte) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return err
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+	c.header = yamlComment(document.HeadComment)
+	root := document.Content[0]
+	if c.header == "" {
+		c.header = yamlComment(root.HeadComment)
+		root.HeadComment = ""
+	}
+	if root.Kind == yaml.MappingNode && len(root.Content) == 2 && root.Content[0].Value == c.Locale && root.Content[1].Kind == yaml.MappingNode {
+		c.localeRoot = true
+		if c.header == "" {
+			c.header = yamlComment(root.Content[0].HeadComment)
+		}
+		root = root.Content[1]
+	}
+	var walk func(prefix string, node *yaml.Node, comment string)
+	walk = func(prefix string, node *yaml.Node, comment string) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			if prefix != "" {
+				c.nested = true
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				walk(joinKey(prefix, node.Content[i].Value), node.Content[i+1], node.Content[i].HeadComment)
+			}
+		case yaml.SequenceNode:
+			c.nested = true
+			for i, item := range node.Content {
+				walk(joinKey(prefix, strconv.Itoa(i)), item, item.HeadComment)
+			}
+		case yaml.AliasNode:
+			walk(prefix, node.Alias, comment)
+		default:
+			message := Message{Key: prefix, Text: node.Value, Line: node.Line, Description: yamlComment(comment)}
+			switch node.Tag {
+			case "!!null":
+				message.Text = ""
+				message.literal = "null"
+			case "!!str":
+			default:
+				message.literal = node.Value
+			}
+			c.add(message)
+		}
+	}
+	walk("", root, "")
+	return nil
+}
+
+// yamlComment strips the markers of a YAML comment block.
+func yamlComment(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(line), "#"), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func joinKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// findCatalogs lists the catalog files given on the command line, walking directories.
+func findCatalogs(paths []string) ([]string, error) {
+	found := []string{}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("error reading catalogs: %v", err)
+		}
+		if !info.IsDir() {
+			found = append(found, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(filePath string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && catalogFormat(filePath) != "" {
+				found = append(found, filePath)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading catalogs: %v", err)
+		}
+	}
+	sortPaths(found, SORT_NATURAL)
+	return found, nil
+}
+
+// CatalogProblem is an issue found while checking catalogs.
+type CatalogProblem struct {
+	Path    string
+	Line    int
+	Kind    string
+	Key     string
+	Message string
+}
+
+const PROBLEM_MISSING = "missing"
+const PROBLEM_ORPHAN = "orphan"
+const PROBLEM_DUPLICATE = "duplicate"
+const PROBLEM_CONFLICT = "conflict"
+const PROBLEM_UNTRANSLATED = "untranslated"
+
+func (p CatalogProblem) String() string {
+	location := p.Path
+	if p.Line > 0 {
+		location += ":" + strconv.Itoa(p.Line)
+	}
+	if p.Key == "" {
+		// a problem of the whole file
+		return location + ": " + p.Kind + " " + p.Message
+	}
+	return location + ": " + p.Kind + " " + strconv.Quote(p.Key) + " " + p.Message
+}
+
+// sourceCatalog finds the catalog of the source locale, or the base resource bundle.
+func sourceCatalog(catalogs []*Catalog, sourceLocale string) (*Catalog, error) {
+	var source, base *Catalog
+	for _, c := range catalogs {
+		if c.Locale == sourceLocale {
+			source = c
+		}
+		if c.Locale == "" {
+			base = c
+		}
+	}
+	if source == nil {
+		source = base
+	}
+	if source == nil {
+		return nil, fmt.Errorf("no catalog for the source locale %s", sourceLocale)
+	}
+	return source, nil
+}
+
+// checkCatalogs compares every target catalog with the source locale's: keys of the source
+// missing from a target or left empty, keys of a target the source doesn't have, and keys
+// defined twice. Texts garbled by a wrong encoding are reported in every catalog.
+// Without a catalog of the source locale, a base resource bundle stands for it.
+func checkCatalogs(catalogs []*Catalog, sourceLocale string) ([]CatalogProblem, error) {
+	source, err := sourceCatalog(catalogs, sourceLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []CatalogProblem{}
+	for _, c := range catalogs {
+		for _, duplicate := range c.Duplicates {
+			problems = append(problems, CatalogProblem{Path: c.Path, Line: duplicate.Line, Kind: PROBLEM_DUPLICATE, Key: duplicate.Key, Message: "is defined more than once"})
+		}
+		problems = append(problems, checkEncoding(c)...)
+		problems = append(problems, checkPlaceholders(source, c)...)
+		if c == source {
+			continue
+		}
+		for _, key := range source.Keys {
+			message, ok := c.Messages[key]
+			if !ok {
+				problems = append(problems, CatalogProblem{Path: c.Path, Kind: PROBLEM_MISSING, Key: key, Message: "is in " + catalogName(source) + " but not translated"})
+			} else if message.Text == "" && message.literal == "" && source.Messages[key].Text != "" {
+				// an empty value, like the ones catalog check -fix adds, still needs translating
+				problems = append(problems, CatalogProblem{Path: c.Path, Line: message.Line, Kind: PROBLEM_UNTRANSLATED, Key: key, Message: "is empty"})
+			}
+		}
+		for _, key := range c.Keys {
+			if _, ok := source.Messages[key]; !ok {
+				problems = append(problems, CatalogProblem{Path: c.Path, Line: c.Messages[key].Line, Kind: PROBLEM_ORPHAN, Key: key, Message: "is not in " + catalogName(source)})
+			}
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+	return problems, nil
+}
+
+// mergeCatalog adds the keys of extracted missing from c after its own, in the order they
+// were extracted, and returns how many were added. Existing messages are kept: when c is a
+// catalog of the source locale and the texts differ, that's reported as a conflict. Keys
+// added to a catalog of another locale are left empty, for translators to fill in.
+func mergeCatalog(c *Catalog, extracted *Catalog, sourceLocale string) (int, []CatalogProblem) {
+	source := c.Locale == sourceLocale || c.Locale == ""
+	added := 0
+	conflicts := []CatalogProblem{}
+	for _, key := range extracted.Keys {
+		message := extracted.Messages[key]
+		existing, ok := c.Messages[key]
+		if !ok {
+			if !source {
+				message.Text = ""
+				message.literal = ""
+			}
+			c.add(message)
+			added++
+			continue
+		}
+		if source && existing.Text != message.Text {
+			conflicts = append(conflicts, CatalogProblem{Path: c.Path, Line: existing.Line, Kind: PROBLEM_CONFLICT, Key: key, Message: "is " + strconv.Quote(existing.Text) + " but was extracted as " + strconv.Quote(message.Text)})
+		}
+		// metadata only the extraction has, like a new description, is picked up
+		if existing.Description == "" && message.Description != "" {
+			existing.Description = message.Description
+			existing.Placeholders = message.Placeholders
+			existing.metadata = message.metadata
+			c.Messages[key] = existing
+		}
+	}
+	return added, conflicts
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+// runCatalog dispatches the catalog subcommands.
+func runCatalog(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker catalog check|merge|fmt|extract [options] <catalog files or directories>")
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "check":
+		runCatalogCheck(args[1:])
+	case "merge":
+		runCatalogMerge(args[1:])
+	case "fmt":
+		runCatalogFormat(args[1:])
+	case "extract":
+		runCatalogExtract(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, "unknown catalog command", args[0])
+		os.Exit(2)
+	}
+}
+
+// loadCatalogs reads the catalogs found at the given paths.
+func loadCatalogs(paths []string) ([]*Catalog, error) {
+	files, err := findCatalogs(paths)
+	if err != nil {
+		return nil, err
+	}
+	catalogs := []*Catalog{}
+	for _, catalogPath := range files {
+		if filepath.Ext(catalogPath) == VUE_EXT {
+			// their <i18n> blocks are checked one component at a time
+			continue
+		}
+		c, err := loadCatalog(catalogPath)
+		if err != nil {
+			return nil, err
+		}
+		catalogs = append(catalogs, c)
+	}
+	return catalogs, nil
+}
+
+// runCatalogCheck validates catalogs against the source locale, exiting 1 on problems.
+func runCatalogCheck(args []string) {
+	flags := flag.NewFlagSet("catalog check", flag.ExitOnError)
+	sourceLocale := flags.String("source", DEFAULT_SOURCE_LOCALE, "locale the other catalogs are translated from")
+	fix := flags.Bool("fix", false, "add the keys missing from the target catalogs")
+	fill := flags.String("fill", FILL_EMPTY, "value of the keys added by -fix: empty or pseudo")
+	duplicateTexts := flags.Bool("duplicate-texts", false, "report source messages with the same or nearly the same text, to consolidate")
+	distance := flags.Int("distance", DEFAULT_TEXT_DISTANCE, "edits two texts may differ by for -duplicate-texts")
+	html := flags.Bool("html", false, "report messages with HTML tags or entities in them")
+	configPath := addConfigFlag(flags)
+	glossaryPath := flags.String("glossary", "", "glossary file of approved and forbidden translations of terms, checked in every locale")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker catalog check [-source en] [-fix] [-fill empty|pseudo] [-glossary file] <catalog files, vue components or directories>")
+		os.Exit(2)
+	}
+	if err := validFill(*fill); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	setupLogger()
+	if err := loadConfig(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := config.Lengths.parse(); err != nil {
+		fmt.Fprintln(os.Stderr, "error in config: lengths:", err)
+		os.Exit(1)
+	}
+	catalogs, err := loadCatalogs(flags.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	glossary := []GlossaryTerm{}
+	if *glossaryPath != "" {
+		if glossary, err = loadGlossary(*glossaryPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	terminology := map[string]int{}
+	// the glossary, length limits and -html apply to the texts of every group of catalogs
+	checkTexts := func(catalogs []*Catalog) []CatalogProblem {
+		source, _ := sourceCatalog(catalogs, *sourceLocale)
+		problems, perLocale := checkGlossary(catalogs, source, glossary)
+		for locale, count := range perLocale {
+			terminology[locale] += count
+		}
+		for _, c := range catalogs {
+			problems = append(problems, checkLengths(&config.Lengths, c)...)
+			if *html {
+				problems = append(problems, checkHTML(c)...)
+			}
+		}
+		return problems
+	}
+	if *fix && len(catalogs) > 0 {
+		if err := fixCatalogs(catalogs, *sourceLocale, *fill); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	components, err := loadComponentCatalogs(flags.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	problems := []CatalogProblem{}
+	if len(catalogs) > 0 || len(components) == 0 {
+		if problems, err = checkCatalogs(catalogs, *sourceLocale); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if *duplicateTexts {
+			source, _ := sourceCatalog(catalogs, *sourceLocale)
+			problems = append(problems, similarTexts(source, *distance)...)
+		}
+		problems = append(problems, checkTexts(catalogs)...)
+	}
+	checked := len(catalogs)
+	for _, component := range components {
+		// a component without messages in the source locale uses the global ones
+		if _, err := sourceCatalog(component, *sourceLocale); err != nil {
+			continue
+		}
+		componentProblems, _ := checkCatalogs(component, *sourceLocale)
+		problems = append(problems, componentProblems...)
+		problems = append(problems, checkTexts(component)...)
+		checked += len(component)
+	}
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(terminology) > 0 {
+		fmt.Printf("Terminology problems per locale: %s.\n", localeCounts(terminology))
+	}
+	fmt.Printf("%d catalogs checked, %d problems found.\n", checked, len(problems))
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+// fixCatalogs scaffolds the keys missing from every target catalog and writes it back.
+func fixCatalogs(catalogs []*Catalog, sourceLocale string, fill string) error {
+	source, err := sourceCatalog(catalogs, sourceLocale)
+	if err != nil {
+		return err
+	}
+	for _, c := range catalogs {
+		if c == source {
+			continue
+		}
+		if added := fixCatalog(c, source, fill); added > 0 {
+			if err := writeCatalog(c, c.Path); err != nil {
+				return err
+			}
+			logger.Info().Msg(fmt.Sprintf("🩹 Added %d missing keys to %s", added, c.Path))
+			fmt.Printf("%s: added %d missing keys\n", c.Path, added)
+		}
+	}
+	return nil
+}
+
+// runCatalogMerge merges freshly extracted keys into an existing catalog, writing it in
+// place or to -o. Texts of the source catalog conflicting with the extracted ones are
+// listed and make it exit 1, the existing text kept.
+func runCatalogMerge(args []string) {
+	flags := flag.NewFlagSet("catalog merge", flag.ExitOnError)
+	output := flags.String("o", "", "write the merged catalog to this file rather than over the existing one")
+	sourceLocale := flags.String("source", DEFAULT_SOURCE_LOCALE, "locale the keys are extracted in")
+	configPath := addConfigFlag(flags)
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker catalog merge [-o output] [-source en] [-config file] <existing catalog> <extracted catalog>")
+		os.Exit(2)
+	}
+
+	setupLogger()
+	if err := loadConfig(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	c, err := loadCatalog(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	extracted, err := loadCatalog(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	added, conflicts := mergeCatalog(c, extracted, *sourceLocale)
+	target := c.Path
+	if *output != "" {
+		target = *output
+	}
+	if err := writeCatalog(c, target); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger.Info().Msg(fmt.Sprintf("🔀 Merged %d keys of %s into %s", added, extracted.Path, target))
+	for _, conflict := range conflicts {
+		fmt.Println(conflict)
+	}
+	fmt.Printf("%d keys added to %s, %d conflicts.\n", added, target, len(conflicts))
+	if len(conflicts) > 0 {
+		os.Exit(1)
+	}
+}
+
+// runCatalogFormat rewrites catalogs in their canonical form. With -check nothing is
+// written: the files that aren't formatted are listed and it exits 1, for CI.
+func runCatalogFormat(args []string) {
+	flags := flag.NewFlagSet("catalog fmt", flag.ExitOnError)
+	check := flags.Bool("check", false, "list the catalogs that aren't formatted instead of rewriting them")
+	configPath := addConfigFlag(flags)
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker catalog fmt [-check] [-config file] <catalog files or directories>")
+		os.Exit(2)
+	}
+
+	setupLogger()
+	if err := loadConfig(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	catalogs, err := loadCatalogs(flags.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	unformatted := 0
+	for _, c := range catalogs {
+		changed, err := formatCatalog(c, *check)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if changed {
+			unformatted++
+			fmt.Println(c.Path)
+		}
+	}
+	if *check {
+		fmt.Printf("%d of %d catalogs not formatted.\n", unformatted, len(catalogs))
+		if unformatted > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	logger.Info().Msg(fmt.Sprintf("🧹 Formatted %d of %d catalogs", unformatted, len(catalogs)))
+}
+
+// runCatalogExtract collects the messages of the source code into a catalog of the source
+// locale, written to -o in the format of its extension or as JSON to the standard output.
+// Conflicting default texts and ids breaking the keys conventions of the config are listed
+// on the standard error and make it exit 1.
+func runCatalogExtract(args []string) {
+	flags := flag.NewFlagSet("catalog extract", flag.ExitOnError)
+	options := addScanFlags(flags)
+	configPath := addConfigFlag(flags)
+	output := flags.String("o", "", "write the catalog to this file, in the format of its extension (a catalog format, .xliff, .po or .pot); "+NAMESPACE_PLACEHOLDER+" in it writes a file per namespace and "+LOCALE_PLACEHOLDER+" stands for the source locale")
+	sourceLocale := flags.String("source", DEFAULT_SOURCE_LOCALE, "locale of the default messages")
+	showNamespaces := flags.Bool("namespaces", false, "list the number of messages per namespace")
+	duplicateTexts := flags.Bool("duplicate-texts", false, "report messages with the same or nearly the same default text, to consolidate")
+	distance := flags.Int("distance", DEFAULT_TEXT_DISTANCE, "edits two default texts may differ by for -duplicate-texts")
+	showCost := flags.Bool("cost", false, "count the words to translate and estimate the cost with the rates of the config")
+	html := flags.Bool("html", false, "report default texts with HTML tags or entities in them")
+	addNoColorFlag(flags)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker catalog extract [-o catalog] [-source en] [-namespaces] [-cost] <directory>")
+		os.Exit(2)
+	}
+	location := flags.Arg(0)
+	format := CATALOG_JSON
+	*output = strings.ReplaceAll(*output, LOCALE_PLACEHOLDER, *sourceLocale)
+	if *output != "" {
+		if format = exportFormat(*output); format == "" {
+			fmt.Fprintln(os.Stderr, "unknown catalog format of", *output)
+			os.Exit(2)
+		}
+	}
+	if err := validateLocation(location); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	setupLogger()
+	if err := applyConfig(*configPath, options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	messages, err := extractLocation(location, *options)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	c, problems := extractedCatalog(*output, format, *sourceLocale, messages)
+	problems = append(problems, lintKeys(&config.Keys, messages)...)
+	problems = append(problems, atOrigins(checkLengths(&config.Lengths, c), messages)...)
+	if *html {
+		problems = append(problems, atOrigins(checkHTML(c), messages)...)
+	}
+	if *duplicateTexts {
+		problems = append(problems, atOrigins(similarTexts(c, *distance), messages)...)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return naturalLess(problems[i].Path, problems[j].Path)
+		}
+		return problems[i].Line < problems[j].Line
+	})
+
+	if plainMode || noColorRequested() {
+		enablePlainMode()
+	} else if !term.IsTerminal(int(os.Stderr.Fd())) {
+		pterm.DisableStyling()
+	}
+	if strings.Contains(*output, NAMESPACE_PLACEHOLDER) {
+		for _, part := range splitNamespaces(c, *output) {
+			if err = os.MkdirAll(filepath.Dir(part.Path), 0755); err != nil {
+				err = fmt.Errorf("error writing catalog %s: %v", part.Path, err)
+				break
+			}
+			if err = writeCatalog(part, part.Path); err != nil {
+				break
+			}
+		}
+	} else if *output != "" {
+		err = writeCatalog(c, *output)
+	} else {
+		var contents []byte
+		if contents, err = c.encode(); err == nil {
+			_, err = os.Stdout.Write(contents)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger.Info().Msg(fmt.Sprintf("📤 Extracted %d messages from %s", len(c.Keys), location))
+	if *showNamespaces {
+		table, err := namespaceTable(c)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Fprint(os.Stderr, table)
+	}
+	if *showCost {
+		table, err := costTable(c, config.Costs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Fprint(os.Stderr, table)
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	fmt.Fprintf(os.Stderr, "%d messages extracted, %d problems found.\n", len(c.Keys), len(problems))
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+// atOrigins points problems found in an extracted catalog at the source line of their message.
+func atOrigins(problems []CatalogProblem, messages []ExtractedMessage) []CatalogProblem {
+	origins := map[string]ExtractedMessage{}
+	for _, extracted := range messages {
+		if _, ok := origins[extracted.ID]; !ok {
+			origins[extracted.ID] = extracted
+		}
+	}
+	for i, problem := range problems {
+		if origin, ok := origins[problem.Key]; ok {
+			problems[i].Path, problems[i].Line = origin.Path, origin.Line
+		}
+	}
+	return problems
+}
+
+// extractLocation scans location for the messages of its source files, sorted by file and line.
+func extractLocation(location string, options ScanOptions) ([]ExtractedMessage, error) {
+	scanner := NewScanner(options)
+	var mu sync.Mutex
+	messages := []ExtractedMessage{}
+	scanner.onContent = func(filePath string, content []byte) {
+		extracted := extractMessages(filePath, string(content))
+		mu.Lock()
+		messages = append(messages, extracted...)
+		mu.Unlock()
+	}
+	if err := scanner.scan(location); err != nil {
+		return nil, err
+	}
+	sortExtracted(messages)
+	return messages, nil
+}
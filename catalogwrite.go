@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyTree rebuilds the nesting of dotted keys, in the order they first appear.
+type keyTree struct {
+	entries []*keyEntry
+	index   map[string]*keyEntry
+}
+
+type keyEntry struct {
+	name     string
+	message  *Message
+	children *keyTree
+}
+
+func newKeyTree() *keyTree {
+	return &keyTree{index: map[string]*keyEntry{}}
+}
+
+func (t *keyTree) append(entry *keyEntry) {
+	t.entries = append(t.entries, entry)
+	if _, ok := t.index[entry.name]; !ok {
+		t.index[entry.name] = entry
+	}
+}
+
+// buildKeyTree splits the keys of a nested catalog on dots. A key running into a message
+// rather than a group, like "a.b" next to "a", stays dotted at that level.
+func buildKeyTree(c *Catalog) *keyTree {
+	root := newKeyTree()
+	for _, key := range c.Keys {
+		message := c.Messages[key]
+		if !c.nested {
+			root.append(&keyEntry{name: key, message: &message})
+			continue
+		}
+		parts := strings.Split(key, ".")
+		t := root
+		name := parts[len(parts)-1]
+		for i, part := range parts[:len(parts)-1] {
+			entry := t.index[part]
+			if entry == nil {
+				entry = &keyEntry{name: part, children: newKeyTree()}
+				t.append(entry)
+			}
+			if entry.children == nil {
+				name = strings.Join(parts[i:], ".")
+				break
+			}
+			t = entry.children
+		}
+		t.append(&keyEntry{name: name, message: &message})
+	}
+	return root
+}
+
+// isArray tells whether a group was a list: its names are 0, 1, 2...
+func (t *keyTree) isArray() bool {
+	for i, entry := range t.entries {
+		if entry.name != strconv.Itoa(i) {
+			return false
+		}
+	}
+	return len(t.entries) > 0
+}
+
+// encode writes the catalog back in its format, with its keys in the order of Keys,
+// indented with two spaces and without trailing whitespace.
+func (c *Catalog) encode() ([]byte, error) {
+	switch c.Format {
+	case CATALOG_JSON:
+		return c.encodeJSON(), nil
+	case CATALOG_ARB:
+		return c.encodeARB(), nil
+	case CATALOG_YAML:
+		return c.encodeYAML()
+	case CATALOG_PROPERTIES:
+		return c.encodeProperties(), nil
+	case CATALOG_XLIFF:
+		return c.encodeXLIFF(), nil
+	case CATALOG_PO, CATALOG_POT:
+		return c.encodePO(c.Format == CATALOG_POT), nil
+	}
+	return nil, fmt.Errorf("unknown format")
+}
+
+// writeCatalog replaces the catalog file at catalogPath, keeping its permissions.
+func writeCatalog(c *Catalog, catalogPath string) error {
+	contents, err := c.encode()
+	if err != nil {
+		return fmt.Errorf("error writing catalog %s: %v", catalogPath, err)
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(catalogPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(catalogPath, contents, mode); err != nil {
+		return fmt.Errorf("error writing catalog %s: %v", catalogPath, err)
+	}
+	return nil
+}
+
+// sortKeys orders the keys of the catalog segment by segment, numbers naturally so list
+// items stay in order.
+func (c *Catalog) sortKeys() {
+	sort.SliceStable(c.Keys, func(i, j int) bool { return keyLess(c.Keys[i], c.Keys[j]) })
+}
+
+func keyLess(a, b string) bool {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		if partsA[i] != partsB[i] {
+			return naturalLess(partsA[i], partsB[i])
+		}
+	}
+	return len(partsA) < len(partsB)
+}
+
+// formatCatalog tells whether the file of a catalog differs from its canonical form, with
+// sorted keys, rewriting it unless check is set.
+func formatCatalog(c *Catalog, check bool) (bool, error) {
+	contents, err := os.ReadFile(c.Path)
+	if err != nil {
+		return false, fmt.Errorf("error reading catalog %s: %v", c.Path, err)
+	}
+	c.sortKeys()
+	formatted, err := c.encode()
+	if err != nil {
+		return false, fmt.Errorf("error formatting catalog %s: %v", c.Path, err)
+	}
+	if bytes.Equal(contents, formatted) {
+		return false, nil
+	}
+	if check {
+		return true, nil
+	}
+	return true, writeCatalog(c, c.Path)
+}
+
+// jsonString quotes s for JSON, leaving <, > and & as they are.
+func jsonString(s string) string {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func jsonValue(message *Message) string {
+	if message.literal != "" {
+		return message.literal
+	}
+	return jsonString(message.Text)
+}
+
+func (c *Catalog) encodeJSON() []byte {
+	var out bytes.Buffer
+	var write func(t *keyTree, indent string)
+	write = func(t *keyTree, indent string) {
+		open, close := "{", "}"
+		array := t.isArray()
+		if array {
+			open, close = "[", "]"
+		}
+		if len(t.entries) == 0 {
+			out.WriteString(open + close)
+			return
+		}
+		out.WriteString(open + "\n")
+		for i, entry := range t.entries {
+			out.WriteString(indent + "  ")
+			if !array {
+				out.WriteString(jsonString(entry.name) + ": ")
+			}
+			if entry.children != nil {
+				write(entry.children, indent+"  ")
+			} else {
+				out.WriteString(jsonValue(entry.message))
+			}
+			if i < len(t.entries)-1 {
+				out.WriteString(",")
+			}
+			out.WriteString("\n")
+		}
+		out.WriteString(indent + close)
+	}
+	write(buildKeyTree(c), "")
+	out.WriteString("\n")
+	return out.Bytes()
+}
+
+// encodeARB writes the "@@" attributes first, then every message followed by its metadata.
+func (c *Catalog) encodeARB() []byte {
+	entries := []string{}
+	indented := func(raw json.RawMessage) string {
+		var out bytes.Buffer
+		if err := json.Indent(&out, raw, "  ", "  "); err != nil {
+			return string(raw)
+		}
+		return out.String()
+	}
+	for _, name := range c.attributes {
+		value := c.raw[name]
+		if name == "@@locale" {
+			value = json.RawMessage(jsonString(c.Locale))
+		}
+		entries = append(entries, jsonString(name)+": "+indented(value))
+	}
+	for _, key := range c.Keys {
+		message := c.Messages[key]
+		entries = append(entries, jsonString(key)+": "+jsonValue(&message))
+		if len(message.metadata) > 0 {
+			entries = append(entries, jsonString("@"+key)+": "+indented(message.metadata))
+		}
+	}
+	if len(entries) == 0 {
+		return []byte("{}\n")
+	}
+	return []byte("{\n  " + strings.Join(entries, ",\n  ") + "\n}\n")
+}
+
+func yamlCommentBlock(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "# " + strings.ReplaceAll(text, "\n", "\n# ")
+}
+
+func (c *Catalog) encodeYAML() ([]byte, error) {
+	scalar := func(message *Message) *yaml.Node {
+		if message.literal != "" {
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: message.literal}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: message.Text}
+	}
+	var build func(t *keyTree) *yaml.Node
+	build = func(t *keyTree) *yaml.Node {
+		array := t.isArray()
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		if array {
+			node.Kind = yaml.SequenceNode
+		}
+		for _, entry := range t.entries {
+			var value *yaml.Node
+			if entry.children != nil {
+				value = build(entry.children)
+			} else {
+				value = scalar(entry.message)
+			}
+			if array {
+				if entry.message != nil {
+					value.HeadComment = yamlCommentBlock(entry.message.Description)
+				}
+				node.Content = append(node.Content, value)
+				continue
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: entry.name}
+			if entry.message != nil {
+				key.HeadComment = yamlCommentBlock(entry.message.Description)
+			}
+			node.Content = append(node.Content, key, value)
+		}
+		return node
+	}
+	root := build(buildKeyTree(c))
+	if c.localeRoot {
+		root = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: c.Locale}, root}}
+	}
+	document := &yaml.Node{Kind: yaml.DocumentNode, HeadComment: yamlCommentBlock(c.header), Content: []*yaml.Node{root}}
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	encoder.Close()
+	return out.Bytes(), nil
+}
+
+// encodeProperties writes key=value lines, comments right above the key they describe.
+// Non-ASCII characters are escaped unless the file was UTF-8 already.
+func (c *Catalog) encodeProperties() []byte {
+	var out strings.Builder
+	comment := func(text string) {
+		for _, line := range strings.Split(text, "\n") {
+			out.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+		}
+	}
+	if c.header != "" {
+		comment(c.header)
+		out.WriteString("\n")
+	}
+	for _, key := range c.Keys {
+		message := c.Messages[key]
+		if message.Description != "" {
+			comment(message.Description)
+		}
+		out.WriteString(escapeProperties(key, true, c.ascii) + "=" + escapeProperties(message.Text, false, c.ascii) + "\n")
+	}
+	return []byte(out.String())
+}
+
+// escapeProperties escapes a key or a value for a .properties file. Leading and trailing
+// spaces are escaped so lines never begin or end with whitespace.
+func escapeProperties(s string, key bool, ascii bool) string {
+	var out strings.Builder
+	runes := []rune(s)
+	last := len(runes) - 1
+	for last >= 0 && runes[last] == ' ' {
+		last--
+	}
+	for i, r := range runes {
+		switch {
+		case r == '\\':
+			out.WriteString(`\\`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case r == '\f':
+			out.WriteString(`\f`)
+		case r == ' ' && i > last:
+			// "\ " would still end the line with a space
+			out.WriteString(`\u0020`)
+		case r == ' ' && (key || i == 0):
+			out.WriteString(`\ `)
+		case key && strings.ContainsRune("=:#!", r), i == 0 && (r == '#' || r == '!'):
+			out.WriteString(`\` + string(r))
+		case r < 0x20 || r == 0x7f || (ascii && r > 0x7f):
+			if r > 0xffff {
+				high, low := utf16.EncodeRune(r)
+				out.WriteString(fmt.Sprintf(`\u%04x\u%04x`, high, low))
+			} else {
+				out.WriteString(fmt.Sprintf(`\u%04x`, r))
+			}
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
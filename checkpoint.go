@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const DEFAULT_CHECKPOINT_INTERVAL = time.Minute
+
+// Checkpoint is the progress of a long scan, saved as it goes so an interrupted run can
+// resume from it: the directories walked through, the candidate files done and the results
+// recorded so far. The files that failed are tried again.
+type Checkpoint struct {
+	Root    string           `json:"root"`
+	Ruleset string           `json:"ruleset"`
+	SavedAt time.Time        `json:"saved_at"`
+	Dirs    []string         `json:"dirs"`
+	Done    []string         `json:"done"`
+	Files   []CheckpointFile `json:"files"`
+}
+
+// CheckpointFile is a file recorded by the scan, with what it matched.
+type CheckpointFile struct {
+	Path     string  `json:"path"`
+	Matches  int     `json:"matches"`
+	Findings []Match `json:"findings,omitempty"`
+}
+
+type checkpointState struct {
+	mu       sync.Mutex
+	path     string
+	interval time.Duration
+	saved    time.Time
+	// resumed are the directories and files the previous run was done with
+	resumedDirs map[string]bool
+	resumedDone map[string]bool
+	current     Checkpoint
+	dirs        map[string]bool
+	// inflight are the candidate files queued but not processed yet and failed the ones
+	// that couldn't be read: the directories holding them are not done
+	inflight map[string]bool
+	failed   map[string]bool
+	// log is the logger of the scan
+	log zerolog.Logger
+}
+
+// openCheckpoint reads the checkpoint of a previous run of the same scan, if any, and
+// returns the state to record this one's progress in.
+func openCheckpoint(checkpointPath string, root string, options ScanOptions, log zerolog.Logger) (*checkpointState, *Checkpoint, error) {
+	cp := &checkpointState{
+		path:        checkpointPath,
+		interval:    options.CheckpointInterval,
+		saved:       time.Now(),
+		resumedDirs: map[string]bool{},
+		resumedDone: map[string]bool{},
+		current:     Checkpoint{Root: root, Ruleset: rulesetHash(options)},
+		dirs:        map[string]bool{},
+		inflight:    map[string]bool{},
+		failed:      map[string]bool{},
+		log:         log,
+	}
+	if cp.interval <= 0 {
+		cp.interval = DEFAULT_CHECKPOINT_INTERVAL
+	}
+	contents, err := os.ReadFile(checkpointPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cp, nil, nil
+		}
+		return nil, nil, fmt.Errorf("error reading checkpoint %s: %v", checkpointPath, err)
+	}
+	previous := &Checkpoint{}
+	if err := json.Unmarshal(contents, previous); err != nil {
+		return nil, nil, fmt.Errorf("error parsing checkpoint %s: %v", checkpointPath, err)
+	}
+	if previous.Root != root || previous.Ruleset != cp.current.Ruleset {
+		cp.log.Warn().Msg("📍 Ignoring checkpoint " + checkpointPath + " of another scan")
+		return cp, nil, nil
+	}
+	for _, dir := range previous.Dirs {
+		cp.resumedDirs[dir] = true
+		cp.dirs[dir] = true
+	}
+	for _, filePath := range previous.Done {
+		cp.resumedDone[filePath] = true
+	}
+	// the files come back through resume
+	cp.current.Done = previous.Done
+	cp.log.Info().Msg(fmt.Sprintf("📍 Resuming from checkpoint %s of %s: %d files done", checkpointPath, previous.SavedAt.Format(time.RFC3339), len(previous.Done)))
+	return cp, previous, nil
+}
+
+// resume replays the results of the previous run into the scanner.
+func (s *Scanner) resume(previous *Checkpoint) {
+	for _, file := range previous.Files {
+		s.filesScanned.Add(1)
+		s.record(file.Path, file.Matches, file.Findings)
+	}
+}
+
+// skipDir tells whether the previous run walked through the whole directory.
+func (cp *checkpointState) skipDir(dir string) bool {
+	return cp != nil && cp.resumedDirs[dir]
+}
+
+// skipFile tells whether the previous run was done with the candidate file.
+func (cp *checkpointState) skipFile(filePath string) bool {
+	return cp != nil && cp.resumedDone[filePath]
+}
+
+func (cp *checkpointState) start(filePath string) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.inflight[filePath] = true
+}
+
+// finish marks a candidate file done, saving the checkpoint when it's due.
+func (cp *checkpointState) finish(s *Scanner, filePath string) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	delete(cp.inflight, filePath)
+	if !cp.failed[filePath] {
+		cp.current.Done = append(cp.current.Done, filePath)
+	}
+	due := time.Since(cp.saved) >= cp.interval
+	cp.mu.Unlock()
+	if due {
+		if err := cp.save(s); err != nil {
+			cp.log.Error().Msg(err.Error())
+		}
+	}
+}
+
+func (cp *checkpointState) recordFile(filePath string, matches int, findings []Match) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.current.Files = append(cp.current.Files, CheckpointFile{Path: filePath, Matches: matches, Findings: findings})
+}
+
+func (cp *checkpointState) fail(filePath string) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.failed[filePath] = true
+}
+
+func (cp *checkpointState) dirDone(dir string) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.dirs[dir] = true
+}
+
+// save writes the checkpoint. Directories with files still being read don't count as
+// walked through yet.
+func (cp *checkpointState) save(s *Scanner) error {
+	cp.mu.Lock()
+	checkpoint := cp.current
+	checkpoint.SavedAt = time.Now()
+	checkpoint.Dirs = []string{}
+	for dir := range cp.dirs {
+		if !holdsAny(dir, cp.inflight) && !holdsAny(dir, cp.failed) {
+			checkpoint.Dirs = append(checkpoint.Dirs, dir)
+		}
+	}
+	sortPaths(checkpoint.Dirs, SORT_LEXICAL)
+	contents, err := json.Marshal(checkpoint)
+	cp.saved = checkpoint.SavedAt
+	cp.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error saving checkpoint %s: %v", cp.path, err)
+	}
+	// written aside first, an interrupted write mustn't lose the previous checkpoint
+	if err := os.WriteFile(cp.path+".tmp", contents, 0644); err != nil {
+		return fmt.Errorf("error saving checkpoint %s: %v", cp.path, err)
+	}
+	if err := os.Rename(cp.path+".tmp", cp.path); err != nil {
+		return fmt.Errorf("error saving checkpoint %s: %v", cp.path, err)
+	}
+	cp.log.Info().Msg(fmt.Sprintf("📍 Saved checkpoint %s: %d files done", cp.path, len(checkpoint.Done)))
+	return nil
+}
+
+// holdsAny tells whether one of the files is under dir.
+func holdsAny(dir string, files map[string]bool) bool {
+	if dir == "." {
+		// joined with ".", paths lose it
+		return len(files) > 0
+	}
+	for filePath := range files {
+		if len(filePath) > len(dir) && strings.HasPrefix(filePath, dir) && strings.ContainsRune(`/\`, rune(filePath[len(dir)])) {
+			return true
+		}
+	}
+	return false
+}
+
+// complete removes the checkpoint of a scan that went through.
+func (cp *checkpointState) complete() {
+	if cp == nil {
+		return
+	}
+	if err := os.Remove(cp.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		cp.log.Error().Msg("error removing checkpoint " + cp.path + ": " + err.Error())
+	}
+}
+
+// finishCheckpoint removes the checkpoint of a scan that went through, and saves it for
+// the next run otherwise.
+func (s *Scanner) finishCheckpoint(err *error) {
+	if *err == nil && !s.limitReached.Load() && !s.timedOut.Load() {
+		s.checkpoint.complete()
+		return
+	}
+	if saveErr := s.checkpoint.save(s); saveErr != nil {
+		s.log.Error().Msg(saveErr.Error())
+	}
+}
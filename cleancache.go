@@ -0,0 +1,141 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+const CLEAN_CACHE_DIRECTORY_NAME = "clean-cache"
+
+// CleanCache remembers, between runs, the files of a root last scanned without a match:
+// a hash of their path, modification time and size and of the rules they were matched with.
+// Those files are skipped while unchanged, under the same rules.
+type CleanCache struct {
+	mu        sync.Mutex
+	storePath string
+	ruleset   string
+	previous  map[string]bool
+	// current holds the entries still valid, the ones written back
+	current map[string]bool
+	hits    int
+	// log is the logger of the scan
+	log zerolog.Logger
+}
+
+// rulesetHash identifies what decides whether a file matches: the rules, the version
+// matching them and whether generated files and comments are scanned.
+func rulesetHash(options ScanOptions) string {
+	rules, _ := json.Marshal(options.Rules)
+	sum := sha256.Sum256([]byte(VERSION + "\x00" + strconv.FormatBool(options.IncludeGenerated) + "\x00" + strconv.FormatBool(options.IgnoreComments) + "\x00" + string(rules)))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCleanCache reads the cache of the files of root found clean by previous scans.
+func loadCleanCache(root string, options ScanOptions, log zerolog.Logger) (*CleanCache, error) {
+	dir, err := stateDirectory()
+	if err != nil {
+		return nil, err
+	}
+	rootSum := sha256.Sum256([]byte(root))
+	c := &CleanCache{
+		storePath: filepath.Join(dir, CLEAN_CACHE_DIRECTORY_NAME, hex.EncodeToString(rootSum[:8])),
+		ruleset:   rulesetHash(options),
+		previous:  map[string]bool{},
+		current:   map[string]bool{},
+		log:       log,
+	}
+	contents, err := os.ReadFile(c.storePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("error reading clean file cache: %v", err)
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		if line != "" {
+			c.previous[line] = true
+		}
+	}
+	return c, nil
+}
+
+func (c *CleanCache) key(filePath string, info fs.FileInfo) string {
+	sum := sha256.Sum256([]byte(filePath + "\x00" + strconv.FormatInt(info.ModTime().UnixNano(), 10) + "\x00" + strconv.FormatInt(info.Size(), 10) + "\x00" + c.ruleset))
+	return hex.EncodeToString(sum[:16])
+}
+
+// clean tells whether the file was found clean by a previous scan and hasn't changed since.
+func (c *CleanCache) clean(filePath string, info fs.FileInfo) bool {
+	if c == nil || info == nil {
+		return false
+	}
+	key := c.key(filePath, info)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.previous[key] {
+		return false
+	}
+	c.current[key] = true
+	c.hits++
+	return true
+}
+
+// remember records a file found clean by this scan.
+func (c *CleanCache) remember(filePath string, info fs.FileInfo) {
+	if c == nil || info == nil {
+		return
+	}
+	key := c.key(filePath, info)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current[key] = true
+}
+
+// save replaces the stored cache with the files clean in this scan, so the ones changed
+// or gone since are dropped.
+func (c *CleanCache) save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.current))
+	for key := range c.current {
+		keys = append(keys, key)
+	}
+	c.mu.Unlock()
+	sort.Strings(keys)
+	if err := os.MkdirAll(filepath.Dir(c.storePath), 0755); err != nil {
+		return fmt.Errorf("error saving clean file cache: %v", err)
+	}
+	if err := os.WriteFile(c.storePath, []byte(strings.Join(keys, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("error saving clean file cache: %v", err)
+	}
+	c.log.Info().Msg(fmt.Sprintf("🧊 Skipped %d unchanged clean files, %d remembered", c.hits, len(keys)))
+	return nil
+}
+
+// statFile reads the size and modification time of a file of fsys.
+func statFile(fsys FileSystem, filePath string) fs.FileInfo {
+	f, err := fsys.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	return info
+}
@@ -0,0 +1,50 @@
+package main
+
+import "strings"
+
+// commentRegions lists the comments of a file, in order: // and /* */ in JavaScript and
+// the <script> of HTML files, <!-- --> in HTML.
+func commentRegions(contents string) []markupRegion {
+	comments := []markupRegion{}
+	for _, region := range markupRegions(contents) {
+		switch region.scope {
+		case SCOPE_COMMENT:
+			comments = append(comments, region)
+		case SCOPE_SCRIPT:
+			comments = append(comments, scriptComments(contents, region.start, region.end)...)
+		}
+	}
+	return comments
+}
+
+// scriptComments lists the comments of the JavaScript in contents[start:end], skipping the
+// string and template literals, where // is often part of a URL.
+func scriptComments(contents string, start int, end int) []markupRegion {
+	comments := []markupRegion{}
+	for i := start; i < end; i++ {
+		c := contents[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			for i++; i < end && contents[i] != c && (c == '`' || contents[i] != '\n'); i++ {
+				if contents[i] == '\\' {
+					i++
+				}
+			}
+		case c == '/' && i+1 < end && contents[i+1] == '/':
+			commentEnd := end
+			if j := strings.IndexByte(contents[i:end], '\n'); j >= 0 {
+				commentEnd = i + j
+			}
+			comments = append(comments, markupRegion{start: i, end: commentEnd, scope: SCOPE_COMMENT})
+			i = commentEnd
+		case c == '/' && i+1 < end && contents[i+1] == '*':
+			commentEnd := end
+			if j := strings.Index(contents[i+2:end], "*/"); j >= 0 {
+				commentEnd = i + 2 + j + len("*/")
+			}
+			comments = append(comments, markupRegion{start: i, end: commentEnd, scope: SCOPE_COMMENT})
+			i = commentEnd - 1
+		}
+	}
+	return comments
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// completePath completes the last element of a typed path against the directories on disk,
+// the way shells do on tab: a single match is completed with a trailing separator, several
+// are completed to their longest common prefix. When that doesn't extend what was typed,
+// the candidates are returned so they can be listed.
+func completePath(typed string) (string, []string) {
+	dir, prefix := filepath.Split(typed)
+	listDir := expandHome(dir)
+	if listDir == "" {
+		listDir = "."
+	}
+	entries, err := os.ReadDir(longPath(listDir))
+	if err != nil {
+		return typed, nil
+	}
+
+	matches := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		if entry.IsDir() {
+			matches = append(matches, name)
+		} else if info, err := os.Stat(filepath.Join(listDir, name)); err == nil && info.IsDir() {
+			// symlinks and junctions pointing to a directory
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return typed, nil
+	case 1:
+		return dir + matches[0] + string(filepath.Separator), nil
+	}
+
+	common := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, common) {
+			_, size := utf8.DecodeLastRuneInString(common)
+			common = common[:len(common)-size]
+		}
+	}
+	if len(common) > len(prefix) {
+		return dir + common, nil
+	}
+	sortPaths(matches, SORT_NATURAL)
+	return typed, matches
+}
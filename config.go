@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const CONFIG_FILE_NAME = "dirwalker.yaml"
+
+// Config is read from dirwalker.yaml in the working directory, or the file given with -config.
+type Config struct {
+	Rules     []Rule     `yaml:"rules"`
+	Bookmarks []Bookmark `yaml:"bookmarks"`
+	Theme     Theme      `yaml:"theme"`
+	// Roots are scanned together by dirwalker scan when it's given no directory.
+	Roots []string `yaml:"roots"`
+	// Schedules are the roots the daemon scans periodically.
+	Schedules []Schedule `yaml:"schedules"`
+	// Webhooks receive a message whenever a scheduled scan finds a change, and after the
+	// scans of dirwalker scan -notify.
+	Webhooks []Webhook `yaml:"webhooks"`
+	// SMTP is the mail server -email-report sends through.
+	SMTP SMTPSettings `yaml:"smtp"`
+	// GitHub is the repository -github-issues files its issues in.
+	GitHub GitHubSettings `yaml:"github"`
+	// Jira is the project -jira-issues files its issues in.
+	Jira JiraSettings `yaml:"jira"`
+	// Keys are the naming conventions of message ids, checked by catalog extract.
+	Keys KeyStyle `yaml:"keys"`
+	// Costs are the rates catalog extract -cost estimates translation costs with.
+	Costs TranslationCosts `yaml:"costs"`
+	// Lengths are the longest texts messages may have, checked by catalog check and extract.
+	Lengths LengthLimits `yaml:"lengths"`
+	// Policies are the minimum coverages of directories, failing dirwalker scan when missed.
+	Policies map[string]CoveragePolicy `yaml:"policies"`
+	// ExcludeFixtures turns the fixtures preset on or off, on by default in JS projects.
+	ExcludeFixtures *bool `yaml:"exclude_fixtures"`
+	// Logs are where the log goes.
+	Logs LogSettings `yaml:"logs"`
+}
+
+var config Config
+
+func addConfigFlag(flags *flag.FlagSet) *string {
+	return flags.String("config", "", "configuration file (default "+CONFIG_FILE_NAME+" in the working directory, if present)")
+}
+
+// loadConfig reads the configuration file. The default file is optional, an explicitly given one is not.
+// The log files are opened then, as the config has it.
+func loadConfig(configPath string) error {
+	defer func() {
+		if err := openLogs(config.Logs); err != nil {
+			logger.Error().Msg(err.Error())
+		}
+	}()
+	explicit := configPath != ""
+	if !explicit {
+		configPath = CONFIG_FILE_NAME
+	}
+	contents, err := os.ReadFile(configPath)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error reading config %s: %v", configPath, err)
+	}
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return fmt.Errorf("error parsing config %s: %v", configPath, err)
+	}
+	logger.Info().Msg("⚙️ Loaded config " + configPath)
+	return nil
+}
+
+// applyConfig loads the configuration and fills in the scan options it drives.
+func applyConfig(configPath string, options *ScanOptions) error {
+	if err := loadConfig(configPath); err != nil {
+		return err
+	}
+	if err := validSortOrder(options.Sort); err != nil {
+		return err
+	}
+	rules, err := effectiveRules(config.Rules)
+	if err != nil {
+		return fmt.Errorf("error in config: %v", err)
+	}
+	options.Rules = rules
+	if err := config.Keys.parse(); err != nil {
+		return fmt.Errorf("error in config: keys: %v", err)
+	}
+	if err := config.Lengths.parse(); err != nil {
+		return fmt.Errorf("error in config: lengths: %v", err)
+	}
+	options.ExcludeFixtures = config.ExcludeFixtures
+	if options.Policies, err = parsePolicies(config.Policies); err != nil {
+		return fmt.Errorf("error in config: policies: %v", err)
+	}
+	for i := range config.Webhooks {
+		if err := config.Webhooks[i].parse(); err != nil {
+			return fmt.Errorf("error in config: webhook %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
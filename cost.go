@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pterm/pterm"
+)
+
+const DEFAULT_CURRENCY = "USD"
+
+// TranslationCosts are the per word rates of the translators, by target locale.
+type TranslationCosts struct {
+	Currency string             `yaml:"currency"`
+	Rates    map[string]float64 `yaml:"rates"`
+}
+
+// translatableText is the text of an ICU message a translator works on: placeholders
+// and HTML tags are dropped, the branches of plural and select arguments kept.
+func translatableText(text string) string {
+	var out strings.Builder
+	var message func(i int) int
+	var argument func(i int) int
+	message = func(i int) int {
+		for i < len(text) {
+			switch text[i] {
+			case '}':
+				return i + 1
+			case '{':
+				out.WriteByte(' ')
+				i = argument(i + 1)
+			case '<':
+				if end := strings.IndexByte(text[i:], '>'); end >= 0 {
+					out.WriteByte(' ')
+					i += end + 1
+					continue
+				}
+				out.WriteByte(text[i])
+				i++
+			default:
+				out.WriteByte(text[i])
+				i++
+			}
+		}
+		return i
+	}
+	argument = func(i int) int {
+		parts := 0
+		depth := 1
+		for i < len(text) && depth > 0 {
+			switch text[i] {
+			case ',':
+				if depth == 1 {
+					parts++
+				}
+			case '{':
+				if parts >= 2 {
+					// the branch of a plural or select
+					i = message(i + 1)
+					out.WriteByte(' ')
+					continue
+				}
+				depth++
+			case '}':
+				depth--
+			}
+			i++
+		}
+		return i
+	}
+	message(0)
+	return out.String()
+}
+
+// wordCount counts the words of a message, the way translators bill them.
+func wordCount(text string) int {
+	return len(strings.FieldsFunc(translatableText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	}))
+}
+
+// catalogWords counts the words of every message of a catalog.
+func catalogWords(c *Catalog) int {
+	words := 0
+	for _, key := range c.Keys {
+		words += wordCount(c.Messages[key].Text)
+	}
+	return words
+}
+
+// costTable estimates what translating the catalog into every locale with a rate costs.
+func costTable(c *Catalog, costs TranslationCosts) (string, error) {
+	words := catalogWords(c)
+	currency := costs.Currency
+	if currency == "" {
+		currency = DEFAULT_CURRENCY
+	}
+	locales := []string{}
+	for locale := range costs.Rates {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	data := pterm.TableData{{"Locale", "Messages", "Words", "Rate", "Cost"}}
+	total := 0.0
+	for _, locale := range locales {
+		rate := costs.Rates[locale]
+		cost := rate * float64(words)
+		total += cost
+		data = append(data, []string{locale, strconv.Itoa(len(c.Keys)), strconv.Itoa(words), fmt.Sprintf("%.3f %s", rate, currency), fmt.Sprintf("%.2f %s", cost, currency)})
+	}
+	if len(locales) == 0 {
+		data = append(data, []string{"-", strconv.Itoa(len(c.Keys)), strconv.Itoa(words), "no rates in the config", ""})
+	} else {
+		data = append(data, []string{"total", "", strconv.Itoa(words * len(locales)), "", fmt.Sprintf("%.2f %s", total, currency)})
+	}
+	table, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return "", fmt.Errorf("error rendering costs: %v", err)
+	}
+	return table + "\n", nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+const COVERAGE_BAR_WIDTH = 20
+const DEFAULT_TOP_DIRECTORIES = 10
+const HOT_SPOTS_WIDTH = 40
+
+// coverageTable renders the coverage of every top-level directory, the least covered
+// first so the modules lagging on i18n adoption stand out, with a bar per directory.
+func (r Report) coverageTable() (string, error) {
+	coverage := append([]Coverage{}, r.Coverage...)
+	sort.SliceStable(coverage, func(i, j int) bool { return coverage[i].Percent() < coverage[j].Percent() })
+
+	data := pterm.TableData{{"Directory", "Files", "With translations", "Coverage", ""}}
+	total := Coverage{Directory: "total"}
+	for _, c := range coverage {
+		data = append(data, coverageRow(c))
+		total.Candidates += c.Candidates
+		total.Matched += c.Matched
+	}
+	data = append(data, coverageRow(total))
+	table, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return "", fmt.Errorf("error rendering coverage: %v", err)
+	}
+	if len(r.Policies) > 0 {
+		data = pterm.TableData{{"Policy", "Files", "With translations", "Coverage", "Minimum", ""}}
+		for _, p := range r.Policies {
+			status := symbol("✓", "ok")
+			if p.Violated() {
+				status = symbol("✗", "below")
+			}
+			data = append(data, append(coverageRow(p.Coverage)[:4], fmt.Sprintf("%5.1f%%", p.MinCoverage), status))
+		}
+		policies, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+		if err != nil {
+			return "", fmt.Errorf("error rendering coverage: %v", err)
+		}
+		table += "\n\n" + policies
+	}
+	counts := r.RuleCounts()
+	if len(counts) == 0 {
+		return table + "\n", nil
+	}
+	data = pterm.TableData{{"Rule", "Lines", "Files"}}
+	for _, c := range counts {
+		data = append(data, []string{c.Rule, strconv.Itoa(c.Lines), strconv.Itoa(c.Files)})
+	}
+	rules, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return "", fmt.Errorf("error rendering coverage: %v", err)
+	}
+	return table + "\n\n" + rules + "\n", nil
+}
+
+func coverageRow(c Coverage) []string {
+	return []string{
+		c.Directory,
+		strconv.Itoa(c.Candidates),
+		strconv.Itoa(c.Matched),
+		fmt.Sprintf("%5.1f%%", c.Percent()),
+		coverageBar(c.Percent(), COVERAGE_BAR_WIDTH),
+	}
+}
+
+// coverageBar draws percent as a bar of width cells.
+func coverageBar(percent float64, width int) string {
+	filled := int(percent/100*float64(width) + 0.5)
+	return strings.Repeat(symbol("█", "#"), filled) + strings.Repeat(symbol("░", "."), width-filled)
+}
+
+// hotSpots charts the top directories by match count, where most of the translation work is.
+func (r Report) hotSpots() string {
+	coverage := []Coverage{}
+	for _, c := range r.Coverage {
+		if c.Matches > 0 {
+			coverage = append(coverage, c)
+		}
+	}
+	if r.TopDirectories <= 0 || len(coverage) == 0 {
+		return ""
+	}
+	sort.SliceStable(coverage, func(i, j int) bool { return coverage[i].Matches > coverage[j].Matches })
+	if len(coverage) > r.TopDirectories {
+		coverage = coverage[:r.TopDirectories]
+	}
+	if pterm.RawOutput {
+		// pterm drops the bars without styling, keep them as plain characters
+		var chart strings.Builder
+		width := 0
+		for _, c := range coverage {
+			if len(c.Directory) > width {
+				width = len(c.Directory)
+			}
+		}
+		for _, c := range coverage {
+			filled := c.Matches * HOT_SPOTS_WIDTH / coverage[0].Matches
+			fmt.Fprintf(&chart, "%-*s %s %d\n", width, c.Directory, strings.Repeat(symbol("█", "#"), filled), c.Matches)
+		}
+		return "\nMatches per directory:\n" + chart.String()
+	}
+	bars := pterm.Bars{}
+	for _, c := range coverage {
+		bars = append(bars, pterm.Bar{Label: c.Directory, Value: c.Matches})
+	}
+	chart, err := pterm.DefaultBarChart.WithHorizontal().WithShowValue().WithWidth(HOT_SPOTS_WIDTH).WithBars(bars).Srender()
+	if err != nil {
+		logger.Error().Msg("error rendering the hot spots chart: " + err.Error())
+		return ""
+	}
+	return "\nMatches per directory:\n" + chart + "\n"
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/rpc"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const SOCKET_NAME = "dirwalker.sock"
+
+// Index remembers the result of every file scanned under a root, so later
+// scans only re-read files whose size or modification time changed, and the
+// listing of every directory, only read again once its modification time changed.
+type Index struct {
+	mu       sync.Mutex
+	entries  map[string]indexEntry
+	listings map[string]dirListing
+}
+
+type indexEntry struct {
+	modTime   time.Time
+	size      int64
+	matches   int
+	generated bool
+	ignored   bool
+	bom       bool
+}
+
+// dirListing is the content of a directory as of its modification time. Adding, removing
+// or renaming an entry changes it; changes to the files are up to their indexEntry.
+type dirListing struct {
+	modTime time.Time
+	entries []fs.DirEntry
+}
+
+func NewIndex() *Index {
+	return &Index{entries: map[string]indexEntry{}, listings: map[string]dirListing{}}
+}
+
+// lookup returns the cached entry when the file is unchanged; otherwise it
+// returns an entry carrying the current stat info for store to record.
+// The file is stat'ed unless info is given.
+func (idx *Index) lookup(filePath string, info fs.FileInfo) (indexEntry, bool) {
+	if idx == nil {
+		return indexEntry{}, false
+	}
+	if info == nil {
+		var err error
+		if info, err = os.Stat(filePath); err != nil {
+			return indexEntry{}, false
+		}
+	}
+	current := indexEntry{modTime: info.ModTime(), size: info.Size()}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	cached, ok := idx.entries[filePath]
+	if ok && cached.modTime.Equal(current.modTime) && cached.size == current.size {
+		return cached, true
+	}
+	return current, false
+}
+
+func (idx *Index) store(filePath string, entry indexEntry) {
+	if idx == nil || entry.modTime.IsZero() {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries[filePath] = entry
+}
+
+// readDir lists a directory, from the previous listing while its modification time is
+// unchanged. fresh is false for those, whose entries may be out of date.
+func (idx *Index) readDir(fsys FileSystem, dir string) (entries []fs.DirEntry, fresh bool, err error) {
+	if _, local := fsys.(localFileSystem); idx == nil || !local {
+		entries, err = fsys.ReadDir(dir)
+		return entries, true, err
+	}
+	info, err := os.Stat(longPath(dir))
+	if err != nil {
+		return nil, false, err
+	}
+	idx.mu.Lock()
+	cached, ok := idx.listings[dir]
+	idx.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.entries, false, nil
+	}
+	if entries, err = fsys.ReadDir(dir); err != nil {
+		return nil, false, err
+	}
+	idx.mu.Lock()
+	idx.listings[dir] = dirListing{modTime: info.ModTime(), entries: entries}
+	idx.mu.Unlock()
+	return entries, true, nil
+}
+
+func (idx *Index) size() int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return len(idx.entries)
+}
+
+// Daemon is the RPC service exposed on the control socket.
+type Daemon struct {
+	mu      sync.Mutex
+	indexes map[string]*Index
+	// scanning serializes scans of the same root
+	scanning map[string]*sync.Mutex
+	options  ScanOptions
+}
+
+type QueryArgs struct {
+	Path string
+}
+
+type QueryReply struct {
+	FoundFiles   []string
+	Coverage     []Coverage
+	FilesScanned int64
+	Matches      int64
+	StartedAt    time.Time
+	Duration     time.Duration
+}
+
+type StatusReply struct {
+	Roots []RootStatus
+}
+
+type RootStatus struct {
+	Path  string
+	Files int
+}
+
+func (d *Daemon) root(dirPath string) (*Index, *sync.Mutex) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.indexes[dirPath]; !ok {
+		d.indexes[dirPath] = NewIndex()
+		d.scanning[dirPath] = &sync.Mutex{}
+	}
+	return d.indexes[dirPath], d.scanning[dirPath]
+}
+
+// Scan walks the root using its warm index.
+func (d *Daemon) Scan(args QueryArgs, reply *QueryReply) error {
+	if isRemote(args.Path) {
+		return fmt.Errorf("the daemon only keeps indexes of local directories")
+	}
+	dirPath, err := filepath.Abs(args.Path)
+	if err != nil {
+		return err
+	}
+	index, lock := d.root(dirPath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	started := time.Now()
+	scanner := NewScanner(d.options)
+	scanner.index = index
+	if err := scanner.scan(dirPath); err != nil {
+		return err
+	}
+	reply.FoundFiles = scanner.foundFiles
+	reply.Coverage = scanner.Coverage()
+	reply.FilesScanned = scanner.filesScanned.Load()
+	reply.Matches = scanner.matchesFound.Load()
+	reply.StartedAt = started
+	reply.Duration = time.Since(started)
+	logger.Info().Msg(fmt.Sprintf("🔥 Daemon scanned %s in %s, %d unchanged directories not listed again", dirPath, reply.Duration, scanner.listingsReused.Load()))
+	return nil
+}
+
+// Status lists the roots the daemon keeps an index for.
+func (d *Daemon) Status(args QueryArgs, reply *StatusReply) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for dirPath, index := range d.indexes {
+		reply.Roots = append(reply.Roots, RootStatus{Path: dirPath, Files: index.size()})
+	}
+	sort.Slice(reply.Roots, func(i, j int) bool { return reply.Roots[i].Path < reply.Roots[j].Path })
+	return nil
+}
+
+func defaultSocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, SOCKET_NAME)
+}
+
+func runDaemon(args []string) {
+	flags := flag.NewFlagSet("daemon", flag.ExitOnError)
+	socketPath := flags.String("socket", defaultSocketPath(), "path of the control socket")
+	var analyzerCommands stringList
+	flags.Var(&analyzerCommands, "analyzer", "command starting an external gRPC analyzer (repeatable)")
+	profiles := addProfileFlags(flags)
+	options := addScanFlags(flags)
+	configPath := addConfigFlag(flags)
+	flags.Parse(args)
+
+	scanRun = true
+	setupLogger()
+	if err := applyConfig(*configPath, options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	stopProfiling, err := profiles.start()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopProfiling()
+	if err := startAnalyzers(analyzerCommands); err != nil {
+		stopProfiling()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopAnalyzers()
+
+	daemon := &Daemon{indexes: map[string]*Index{}, scanning: map[string]*sync.Mutex{}, options: *options}
+	server := rpc.NewServer()
+	if err := server.Register(daemon); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// a stale socket from a crashed daemon would make Listen fail
+	if conn, err := net.Dial("unix", *socketPath); err == nil {
+		conn.Close()
+		fmt.Fprintln(os.Stderr, "a daemon is already listening on "+*socketPath)
+		os.Exit(1)
+	}
+	os.Remove(*socketPath)
+	listener, err := net.Listen("unix", *socketPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	stopSchedules, err := daemon.startSchedules(config.Schedules)
+	if err != nil {
+		listener.Close()
+		stopAnalyzers()
+		stopProfiling()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopSchedules()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+
+	logger.Info().Msg("🔥 Daemon listening on " + *socketPath)
+	fmt.Println("Daemon listening on " + *socketPath)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			// the listener is closed on shutdown
+			break
+		}
+		go server.ServeConn(conn)
+	}
+	logger.Info().Msg("🔥 Daemon stopped")
+}
+
+func runQuery(args []string) {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	socketPath := flags.String("socket", defaultSocketPath(), "path of the control socket")
+	status := flags.Bool("status", false, "list the roots the daemon keeps warm")
+	flags.Parse(args)
+
+	client, err := rpc.Dial("unix", *socketPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error connecting to the daemon, is `dirwalker daemon` running?", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	if *status {
+		var reply StatusReply
+		if err := client.Call("Daemon.Status", QueryArgs{}, &reply); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for _, root := range reply.Roots {
+			fmt.Printf("%s (%d files indexed)\n", root.Path, root.Files)
+		}
+		return
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker query [-socket path] <directory>")
+		os.Exit(2)
+	}
+	// the daemon runs in a directory of its own, and tells remote locations apart itself
+	root := flags.Arg(0)
+	if !isRemote(root) {
+		if root, err = filepath.Abs(root); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	var reply QueryReply
+	if err := client.Call("Daemon.Scan", QueryArgs{Path: root}, &reply); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, name := range reply.FoundFiles {
+		fmt.Println(name)
+	}
+	fmt.Printf("%d files found with translation content (%d scanned in %s).\n", len(reply.FoundFiles), reply.FilesScanned, reply.Duration)
+}
@@ -1,19 +1,29 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"path"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/pterm/pterm"
 	"github.com/pterm/pterm/putils"
 	"github.com/rs/zerolog"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 const JS_EXT = ".js"
@@ -29,77 +39,530 @@ const MAXBACKUPS = 10
 const MAXSIZE = 10
 const MAXAGE = 10
 const TEST_FILE_STRING = "_spec"
+const DEFAULT_WORKERS = 1
+const DEFAULT_BUFFER_SIZE = 32 * 1024
 
 const VERSION = "1.0.0"
 
 var logger zerolog.Logger
-var foundFiles = []string{}
 
 type Model struct {
 	textInput textinput.Model
 	spinner   spinner.Model
+	picker    filepicker.Model
+	help      help.Model
 
 	typing   bool
+	picking  bool
+	choosing bool
 	loading  bool
 	err      error
-	location string
+	options  ScanOptions
+
+	// history holds every completed scan of the session, current being the one shown
+	history []Results
+	current int
+	// selected is the highlighted entry of the results list, offset the first one shown
+	selected int
+	offset   int
+	// showFailed lists the files that couldn't be read instead of the found ones
+	showFailed bool
+	// showMatches lists the matched lines of the found files instead of the files
+	showMatches bool
+	// showTreemap shows the density map of the top-level directories instead of the list
+	showTreemap bool
+	// marks is the triage state of found files, saved on every change
+	marks Marks
+	// sessionPath is where the session is saved on every change, to be resumed later
+	sessionPath string
+	// showLog shows the pane with the latest log lines
+	showLog bool
+	// notifyAfter is how long a scan must run before its completion is sent as a
+	// desktop notification, 0 disabling them
+	notifyAfter time.Duration
+	// width and height are the terminal's size, 0 until it is known
+	width  int
+	height int
+	// sessionFiles holds every file found since the program started, across re-scans
+	sessionFiles map[string]bool
+
+	// recent holds the previously scanned locations; up/down browse the ones starting with
+	// draft, recentIndex being the one shown or -1 while editing the draft
+	recent      []string
+	recentIndex int
+	draft       string
+	// completions lists the candidates when tab couldn't complete any further
+	completions []string
+	// inputErr is shown next to the prompt when the typed path can't be scanned
+	inputErr error
+
+	bookmarkIndex int
+	// startLocation, when set, is scanned as soon as the program starts
+	startLocation string
 }
 
 type Results struct {
-	Err      error
-	Location string
+	Err          error         `json:"-"`
+	ScannedAt    time.Time     `json:"scanned_at"`
+	Duration     time.Duration `json:"duration"`
+	Location     string        `json:"location"`
+	FoundFiles   []string      `json:"found_files"`
+	Matches      []Match       `json:"matches,omitempty"`
+	LimitReached bool          `json:"limit_reached"`
+	FilesSkipped int64         `json:"files_skipped"`
+	FilesIgnored int64         `json:"files_ignored"`
+	Suppressed   int64         `json:"suppressed"`
+	FilesWithBOM int           `json:"files_with_bom"`
+	// NewFiles counts the found files not already found earlier in the session.
+	NewFiles int          `json:"new_files"`
+	Failed   []FailedFile `json:"failed_files"`
+	Coverage []Coverage   `json:"coverage"`
+}
+
+// ScanOptions tunes how a Scanner reads the tree.
+type ScanOptions struct {
+	// Workers is the number of files read concurrently.
+	Workers int
+	// BufferSize is the size of the chunks files are read in, NetworkBufferSize on a remote
+	// root or a network file system; 0 keeps BufferSize there too.
+	BufferSize        int
+	NetworkBufferSize int
+	// StatLight takes the size and modification time of files from directory listings and
+	// doesn't resolve symlinks, saving round-trips on network file systems.
+	StatLight bool
+	// MaxMatchesPerFile stops counting a file's matches once reached, 0 means no limit.
+	MaxMatchesPerFile int
+	// MaxTotalMatches stops the scan once reached, 0 means no limit.
+	MaxTotalMatches int
+	// Rules are the patterns looked for in candidate files.
+	Rules []Rule
+	// Archives descends into .zip and .tar(.gz) files found during the walk.
+	Archives bool
+	// IncludeGenerated scans files with a generated-code header, which are skipped by default.
+	IncludeGenerated bool
+	// IgnoreComments leaves out the matches in comments, often commented-out code.
+	IgnoreComments bool
+	// Hidden includes dotfiles and dot-directories (.git, .cache, ...), which are skipped by default.
+	Hidden bool
+	// Sort orders the reported paths: natural (the default), lexical or walk.
+	Sort string
+	// Nice reads one file at a time, at ReadRate or DEFAULT_NICE_READ_RATE, for background scans.
+	Nice bool
+	// ReadRate caps the read throughput in bytes per second, 0 means no limit.
+	ReadRate int
+	// MaxMemory is the memory budget of the scan in MiB, 0 means no limit. Workers and
+	// BufferSize are lowered to fit it and files are streamed rather than read whole.
+	MaxMemory int
+	// CleanCache skips the files found without a match by a previous run while they and the
+	// rules are unchanged.
+	CleanCache bool
+	// ReadRetries is how many times a read failing with a transient error is tried again,
+	// RetryBackoff the wait before the first retry, doubled for every other.
+	ReadRetries  int
+	RetryBackoff time.Duration
+	// Timeout stops the scan once reached, FileTimeout gives up on a file or a directory
+	// listing taking longer; 0 means no limit. What's given up on is listed as failed.
+	Timeout     time.Duration
+	FileTimeout time.Duration
+	// Checkpoint is the file the progress of the scan is saved to every CheckpointInterval,
+	// and resumed from when it's there.
+	Checkpoint         string
+	CheckpointInterval time.Duration
+	// Policies are the minimum coverages of directories, by slash-separated path from the root.
+	Policies map[string]CoveragePolicy
+	// ExcludeFixtures leaves out Storybook stories, test fixtures, snapshots and mocks; nil
+	// leaves them out of JS projects only.
+	ExcludeFixtures *bool
+}
+
+func addScanFlags(flags *flag.FlagSet) *ScanOptions {
+	options := &ScanOptions{}
+	flags.IntVar(&options.Workers, "workers", DEFAULT_WORKERS, "number of files read concurrently")
+	flags.IntVar(&options.BufferSize, "buffer-size", DEFAULT_BUFFER_SIZE, "read buffer size in bytes")
+	flags.IntVar(&options.NetworkBufferSize, "network-buffer-size", DEFAULT_NETWORK_BUFFER_SIZE, "read buffer size in bytes on remote locations and NFS or SMB mounts (0 for -buffer-size)")
+	flags.BoolVar(&options.StatLight, "stat-light", false, "avoid the extra stat calls per file, for network file systems: files reached through symlinks may be reported twice")
+	flags.IntVar(&options.MaxMatchesPerFile, "max-matches-per-file", 0, "stop counting matches in a file after this many (0 for no limit)")
+	flags.IntVar(&options.MaxTotalMatches, "max-total-matches", 0, "stop the scan after this many matches (0 for no limit)")
+	flags.BoolVar(&options.Archives, "archives", false, "also scan inside .zip, .tar, .tar.gz and .tgz files")
+	flags.BoolVar(&options.Hidden, "hidden", false, "include dotfiles and dot-directories, skipped by default")
+	flags.StringVar(&options.Sort, "sort", SORT_NATURAL, "order of reported paths: natural (file2 before file10), lexical or walk")
+	flags.BoolVar(&options.IncludeGenerated, "include-generated", false, "scan files with a \"DO NOT EDIT\"/\"@generated\" header too")
+	flags.BoolVar(&options.IgnoreComments, "ignore-comments", false, "don't count the matches in //, /* */ and <!-- --> comments")
+	flags.BoolVar(&options.Nice, "nice", false, "go easy on the disk: read one file at a time, under -read-rate (4 MiB/s by default)")
+	flags.IntVar(&options.ReadRate, "read-rate", 0, "maximum read throughput in bytes per second (0 for no limit)")
+	flags.IntVar(&options.ReadRetries, "read-retries", DEFAULT_READ_RETRIES, "times a read failing with a transient error (EINTR, EAGAIN, network file system timeouts) is retried")
+	flags.DurationVar(&options.RetryBackoff, "retry-backoff", DEFAULT_RETRY_BACKOFF, "wait before the first retry of a read, doubled for every other")
+	flags.DurationVar(&options.Timeout, "timeout", 0, "stop the scan after this long, e.g. 30m (0 for no limit)")
+	flags.DurationVar(&options.FileTimeout, "file-timeout", 0, "give up on a file or directory taking longer than this to read, e.g. 10s (0 for no limit)")
+	flags.StringVar(&options.Checkpoint, "checkpoint", "", "save the progress of the scan to this file as it goes, and resume from it when it exists")
+	flags.DurationVar(&options.CheckpointInterval, "checkpoint-interval", DEFAULT_CHECKPOINT_INTERVAL, "how often -checkpoint is saved")
+	flags.BoolVar(&options.CleanCache, "clean-cache", false, "remember the files without matches between runs and skip them while unchanged")
+	flags.IntVar(&options.MaxMemory, "max-memory", 0, "memory budget in MiB: fewer workers, smaller buffers and files streamed to stay within it (0 for no limit)")
+	return options
+}
+
+// Scanner walks a directory tree collecting the files with translation content.
+type Scanner struct {
+	root    string
+	fs      FileSystem
+	options ScanOptions
+	// foundFiles holds the canonical paths of the matched files, fileMatches their matches.
+	foundFiles   []string
+	fileMatches  map[string]int
+	filesScanned atomic.Int64
+	filesMatched atomic.Int64
+	filesSkipped atomic.Int64
+	filesIgnored atomic.Int64
+	filesFailed  atomic.Int64
+	matchesFound atomic.Int64
+
+	matchesSuppressed atomic.Int64
+	bytesRead         atomic.Int64
+	limitReached      atomic.Bool
+
+	mu          sync.Mutex
+	coverage    map[string]*Coverage
+	failedFiles []FailedFile
+	// bomFiles holds the files starting with a byte order mark, stripped before matching
+	bomFiles []string
+	files    chan candidateFile
+	err      error
+	// policyCoverage holds the coverage of the directories with a policy
+	policyCoverage map[string]*Coverage
+	// excludeFixtures skips the entries of the fixtures preset
+	excludeFixtures bool
+
+	// recorded holds the canonical paths of every file scanned so far
+	recorded map[string]bool
+	// visitedLinks holds the targets of the junctions already walked
+	visitedLinks map[string]bool
+
+	// onMatch, when set, is called with the path of every matched file as the walk finds it.
+	onMatch func(filePath string)
+	// onMiss, when set, is called with the canonical path of every candidate file scanned
+	// without a match.
+	onMiss func(filePath string)
+	// onFinding, when set, is called with every matched line of the files found, from the
+	// worker goroutines. Files whose result comes from the index have no findings.
+	onFinding func(finding Match)
+	// onContent, when set, is called with the content of every candidate file scanned, once
+	// per file, from the worker goroutines. Files are then always read, the index notwithstanding.
+	onContent func(filePath string, content []byte)
+	// index, when set, lets unchanged files reuse the result of a previous scan, and
+	// unchanged directories their listing.
+	index          *Index
+	listingsReused atomic.Int64
+	// throttle, when set, keeps the reads under the read rate.
+	throttle *throttle
+	// streamWindow, when set, is the most of a file held in memory at once: files are then
+	// matched a window of lines at a time.
+	streamWindow int
+	// cleanCache, when set, holds the files known to have no match.
+	cleanCache *CleanCache
+	// deadline, when set, is when the scan times out.
+	deadline time.Time
+	timedOut atomic.Bool
+	// checkpoint, when set, records the progress of the scan.
+	checkpoint *checkpointState
+	// log is the logger of the scan, its lines carrying the root and the ruleset.
+	log zerolog.Logger
+}
+
+type candidateFile struct {
+	path    string
+	archive bool
+	// entry is the directory listing's entry of the file, if it was walked to.
+	entry fs.DirEntry
+}
+
+func NewScanner(options ScanOptions) *Scanner {
+	if options.Workers < 1 {
+		options.Workers = DEFAULT_WORKERS
+	}
+	if options.BufferSize < 1 {
+		options.BufferSize = DEFAULT_BUFFER_SIZE
+	}
+	if len(options.Rules) == 0 {
+		options.Rules = DEFAULT_RULES
+	}
+	if options.Sort == "" {
+		options.Sort = SORT_NATURAL
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = DEFAULT_RETRY_BACKOFF
+	}
+	if options.Nice {
+		options.Workers = NICE_WORKERS
+		if options.ReadRate == 0 {
+			options.ReadRate = DEFAULT_NICE_READ_RATE
+		}
+	}
+	streamWindow := applyMemoryBudget(&options)
+	return &Scanner{options: options, throttle: newThrottle(options.ReadRate), streamWindow: streamWindow, log: logger}
+}
+
+// FailedFile is a candidate file that couldn't be read, or a directory that couldn't be
+// listed or was left unwalked by a timeout, when Dir is set.
+type FailedFile struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+	Dir   bool   `json:"dir,omitempty"`
+}
+
+// Coverage counts candidate files and files with translation content under a top-level directory.
+type Coverage struct {
+	Directory  string `json:"directory"`
+	Candidates int    `json:"candidates"`
+	Matched    int    `json:"matched"`
+	Matches    int    `json:"matches"`
 }
 
 func generateWelcomeHeader() {
+	if plainMode {
+		fmt.Println("Strings! " + VERSION)
+		fmt.Println("Please grab the location where you find the strings.")
+		return
+	}
 	pterm.DefaultCenter.WithCenterEachLineSeparately().Println("Strings!\n" + VERSION)
-	s, _ := pterm.DefaultBigText.WithLetters(putils.LettersFromString("Strings")).Srender()
+	letters := putils.LettersFromString("Strings")
+	if styles.Banner != nil {
+		letters = putils.LettersFromStringWithRGB("Strings", *styles.Banner)
+	}
+	s, _ := pterm.DefaultBigText.WithLetters(letters).Srender()
 	pterm.DefaultCenter.Println(s)
 
 	pterm.DefaultCenter.WithCenterEachLineSeparately().Println("👋 Please grab the location where you find the strings.")
 }
 
+// collectMatches gathers the matched lines of the files the scanner finds, to be read
+// once it's done.
+func collectMatches(scanner *Scanner) *[]Match {
+	var mu sync.Mutex
+	matches := []Match{}
+	scanner.onFinding = func(match Match) {
+		mu.Lock()
+		matches = append(matches, match)
+		mu.Unlock()
+	}
+	return &matches
+}
+
 func (m Model) startWork(dirPath string) tea.Cmd {
 
 	return func() tea.Msg {
-		err := walkDir(dirPath)
+		scanner := NewScanner(m.options)
+		matches := collectMatches(scanner)
+		started := time.Now()
+		err := scanner.scan(dirPath)
 		// loc, err := walkDir(context.Background(), dirPath)
 		if err != nil {
 			return Results{Err: err}
 		}
 
-		return Results{Location: dirPath}
+		return Results{
+			ScannedAt:    started,
+			Duration:     time.Since(started),
+			Location:     dirPath,
+			FoundFiles:   scanner.foundFiles,
+			Matches:      sortFindings(*matches, m.options.Sort),
+			LimitReached: scanner.limitReached.Load(),
+			FilesSkipped: scanner.filesSkipped.Load(),
+			FilesIgnored: scanner.filesIgnored.Load(),
+			Suppressed:   scanner.matchesSuppressed.Load(),
+			FilesWithBOM: len(scanner.bomFiles),
+			Failed:       scanner.failedFiles,
+			Coverage:     scanner.Coverage(),
+		}
 	}
 }
 
 func (m Model) Init() tea.Cmd {
+	if m.startLocation != "" {
+		return tea.Batch(m.spinner.Tick, m.startWork(m.startLocation))
+	}
 	return textinput.Blink
 }
 
+// resize lays the screens out for a terminal of the given size.
+func (m Model) resize(width int, height int) Model {
+	m.width, m.height = width, height
+	m.help.Width = width
+	// the prompt and the cursor take three columns
+	m.textInput.Width = width - 3
+	m.picker.Height = pickerHeight(height)
+	return m.moveSelection(0)
+}
+
+// autosave saves the session, logging rather than interrupting the review on failure.
+func (m Model) autosave() {
+	if err := m.saveSession(); err != nil {
+		logger.Error().Msg(err.Error())
+	}
+}
+
+// beginScan switches to the loading screen while dirPath is scanned.
+func (m Model) beginScan(dirPath string) (tea.Model, tea.Cmd) {
+	m.typing = false
+	m.loading = true
+	return m, tea.Batch(
+		m.spinner.Tick,
+		m.startWork(dirPath),
+	)
+}
+
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m = m.resize(size.Width, size.Height)
+	}
+	if m.picking {
+		return m.updatePicker(msg)
+	}
+	if m.choosing {
+		return m.updateBookmarks(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c":
+		switch {
+		case key.Matches(msg, keys.Quit):
 			return m, tea.Quit
-		case "enter":
-			if m.typing {
+		case key.Matches(msg, keys.Help) && (!m.typing || m.textInput.Value() == ""):
+			// on the prompt ? is only help while nothing was typed, it's a valid path character
+			m.help.ShowAll = !m.help.ShowAll
+			return m, nil
+		case key.Matches(msg, keys.Log):
+			m.showLog = !m.showLog
+			return m, nil
+		}
+
+		if m.typing {
+			switch {
+			case key.Matches(msg, keys.Browse):
+				m.picking = true
+				m.picker = newDirectoryPicker(strings.TrimSpace(m.textInput.Value()), m.options.Hidden, m.height)
+				return m, m.picker.Init()
+
+			case key.Matches(msg, keys.Complete):
+				completed, candidates := completePath(m.textInput.Value())
+				m.textInput.SetValue(completed)
+				m.textInput.CursorEnd()
+				m.completions = candidates
+				return m, nil
+
+			case key.Matches(msg, keys.Bookmarks):
+				m.choosing = true
+				return m, nil
+
+			case key.Matches(msg, keys.Scan):
 				query := strings.TrimSpace(m.textInput.Value())
 				if query != "" {
-					m.typing = false
-					m.loading = true
-					return m, tea.Batch(
-						spinner.Tick,
-						m.startWork(query),
-					)
+					if err := validateLocation(query); err != nil {
+						m.inputErr = err
+						return m, nil
+					}
+					return m.beginScan(query)
 				}
-			}
 
-		case "esc":
-			if !m.typing && !m.loading {
+			case key.Matches(msg, keys.RecentUp):
+				if m.recentIndex == -1 {
+					m.draft = m.textInput.Value()
+				}
+				if matches := recentSuggestions(m.recent, m.draft, 0); m.recentIndex < len(matches)-1 {
+					m.recentIndex++
+					m.textInput.SetValue(matches[m.recentIndex])
+					m.textInput.CursorEnd()
+				}
+				return m, nil
+
+			case key.Matches(msg, keys.RecentDown):
+				if m.recentIndex > -1 {
+					m.recentIndex--
+					if m.recentIndex == -1 {
+						m.textInput.SetValue(m.draft)
+					} else {
+						m.textInput.SetValue(recentSuggestions(m.recent, m.draft, 0)[m.recentIndex])
+					}
+					m.textInput.CursorEnd()
+				}
+				return m, nil
+			}
+		} else if !m.loading {
+			switch {
+			case key.Matches(msg, keys.Again):
 				m.typing = true
 				m.err = nil
-				foundFiles = []string{} // clear our slice , reset
+				return m, textinput.Blink
+
+			case key.Matches(msg, keys.Previous):
+				if m.err == nil && m.current > 0 {
+					m.current--
+					m.selected, m.offset = 0, 0
+					m.showFailed, m.showMatches = false, false
+				}
+				return m, nil
+
+			case key.Matches(msg, keys.Next):
+				if m.err == nil && m.current < len(m.history)-1 {
+					m.current++
+					m.selected, m.offset = 0, 0
+					m.showFailed, m.showMatches = false, false
+				}
+				return m, nil
+
+			case key.Matches(msg, keys.Mark):
+				if m.err == nil && !m.showFailed && !m.showMatches && len(m.history[m.current].FoundFiles) > 0 {
+					m.marks.cycle(m.history[m.current].FoundFiles[m.selected])
+					if err := m.marks.save(); err != nil {
+						logger.Error().Msg(err.Error())
+					}
+					m.autosave()
+				}
 				return m, nil
+
+			case key.Matches(msg, keys.ToggleFailed):
+				if m.err == nil && len(m.history[m.current].Failed) > 0 {
+					m.showFailed = !m.showFailed
+					m.showTreemap, m.showMatches = false, false
+					m.selected, m.offset = 0, 0
+				}
+				return m, nil
+
+			case key.Matches(msg, keys.ToggleMatches):
+				if m.err == nil && len(m.history[m.current].Matches) > 0 {
+					m.showMatches = !m.showMatches
+					m.showTreemap, m.showFailed = false, false
+					m.selected, m.offset = 0, 0
+				}
+				return m, nil
+
+			case key.Matches(msg, keys.Treemap):
+				if m.err == nil {
+					m.showTreemap = !m.showTreemap
+					m.showFailed, m.showMatches = false, false
+				}
+				return m, nil
+
+			case key.Matches(msg, keys.Retry):
+				if m.err == nil && m.showFailed {
+					failed := m.history[m.current].Failed
+					return m, m.retryFiles(failed[m.selected : m.selected+1])
+				}
+				return m, nil
+
+			case key.Matches(msg, keys.RetryAll):
+				if m.err == nil && m.showFailed {
+					return m, m.retryFiles(m.history[m.current].Failed)
+				}
+				return m, nil
+
+			case key.Matches(msg, keys.ListUp):
+				return m.moveSelection(-1), nil
+			case key.Matches(msg, keys.ListDown):
+				return m.moveSelection(1), nil
+			case key.Matches(msg, keys.PageUp):
+				return m.moveSelection(-m.listHeight()), nil
+			case key.Matches(msg, keys.PageDown):
+				return m.moveSelection(m.listHeight()), nil
 			}
 		}
 
@@ -111,11 +574,45 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		m.location = msg.Location
+		for _, filePath := range msg.FoundFiles {
+			if !m.sessionFiles[filePath] {
+				m.sessionFiles[filePath] = true
+				msg.NewFiles++
+			}
+		}
+		recent, err := rememberDirectory(m.recent, msg.Location)
+		if err != nil {
+			logger.Error().Msg(err.Error())
+		}
+		m.recent = recent
+		m.recentIndex = -1
+		m.history = append(m.history, msg)
+		m.current = len(m.history) - 1
+		m.selected, m.offset = 0, 0
+		m.showFailed, m.showMatches = false, false
+		m.autosave()
+		if m.notifyAfter > 0 && msg.Duration >= m.notifyAfter {
+			return m, notifyScanDone(msg)
+		}
+		return m, nil
+
+	case Retried:
+		m = m.mergeRetried(msg)
+		m.autosave()
 		return m, nil
+
+	case tea.MouseMsg:
+		if !m.typing && !m.loading && m.err == nil {
+			return m.updateMouse(msg), nil
+		}
 	}
 
 	if m.typing {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			m.recentIndex = -1
+			m.completions = nil
+			m.inputErr = nil
+		}
 		var cmd tea.Cmd
 		m.textInput, cmd = m.textInput.Update(msg)
 		return m, cmd
@@ -131,104 +628,746 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	view := m.screenView() + "\n\n" + m.help.View(m.screenKeys())
+	if m.showLog {
+		view += "\n\n" + m.logPane()
+	}
+	return view
+}
+
+func (m Model) screenView() string {
+	if m.picking {
+		return m.pickerView()
+	}
+	if m.choosing {
+		return m.bookmarksView()
+	}
+
 	if m.typing {
-		return fmt.Sprintf("Enter Directory Path :\n%s", m.textInput.View())
+		hint := "tab to complete, ctrl+o to browse"
+		if len(config.Bookmarks) > 0 {
+			hint += ", ctrl+b for bookmarks"
+		}
+		prompt := fmt.Sprintf("Enter Directory Path (%s) :\n%s", hint, m.textInput.View())
+		if m.inputErr != nil {
+			prompt += "  " + styles.Error.Render(symbol("✗", "error:")+" "+m.inputErr.Error())
+		}
+		if len(m.completions) > 0 {
+			return prompt + "\n\n" + strings.Join(m.completions, "  ")
+		}
+		if suggestions := recentSuggestions(m.recent, m.textInput.Value(), RECENT_SUGGESTIONS); m.recentIndex == -1 && len(suggestions) > 0 {
+			prompt += "\n\nRecent (" + symbol("↑/↓", "up/down") + " to pick):\n  " + strings.Join(suggestions, "\n  ")
+		}
+		return prompt
 	}
 
 	if m.loading {
-		return fmt.Sprintf("%s Please wait while the 🧝 sort ..", m.spinner.View())
+		return fmt.Sprintf("%s Please wait while the %s sort ..", m.spinner.View(), symbol("🧝", "elves"))
 	}
 
 	if err := m.err; err != nil {
-		return fmt.Sprintf("An error was encountered: %v", err)
+		return styles.Error.Render(fmt.Sprintf("An error was encountered: %v", err))
 	}
 
-	return fmt.Sprintf(strconv.FormatInt(int64(len(foundFiles)), 10) + " files found with translation content.\nPlease check the log file for more details.\nPress CTRL+C to exit.\nPress ESC to start again.\n")
+	body := m.resultsList()
+	if m.showTreemap {
+		body = "\n" + m.treemapView() + "\n"
+	}
+	return m.resultsSummary() + body + "Please check the log file for more details.\nPress CTRL+C to exit.\nPress ESC to start again.\n"
 }
 
+// setupLogger starts the log, its lines carrying the run's ID. Its file is opened by openLogs
+// once the config is read, loadConfig does it.
 func setupLogger() {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
-	currentWorkingDirectory, _ := os.Getwd()
-	loggerPath := path.Join(currentWorkingDirectory, LOGDIRECTORY)
+	logger = zerolog.New(zerolog.MultiLevelWriter(logFile, logLines)).With().Timestamp().Str("run_id", runID).Logger()
+	logger.Info().Msg("👋 Welcome ")
+}
 
-	customLogger := lumberjack.Logger{
-		Filename:   path.Join(loggerPath, LOG_FILE_NAME),
-		MaxBackups: MAXBACKUPS, // files
-		MaxSize:    MAXSIZE,    // megabytes
-		MaxAge:     MAXAGE,     // days
+func (s *Scanner) readFile(file candidateFile) error {
+	filePath := file.path
+	limit := s.matchLimit()
+	if s.limitReached.Load() || s.expired() {
+		return nil
 	}
-	logger = zerolog.New(&customLogger).With().Timestamp().Logger()
-	logger.Info().Msg("👋 Welcome ")
+	s.filesScanned.Add(1)
+	var info fs.FileInfo
+	if s.cleanCache != nil || (s.index != nil && s.options.StatLight) {
+		info = s.fileInfo(file)
+	}
+	if s.cleanCache.clean(filePath, info) {
+		s.record(filePath, 0, nil)
+		return nil
+	}
+	entry, cached := s.index.lookup(filePath, info)
+	matches := entry.matches
+	var findings []Match
+	var contents []byte
+	if cached && limit > 0 && matches > limit {
+		matches = limit
+	}
+	if !cached || s.onContent != nil {
+		var err error
+		// the content handed to onContent has to be whole
+		streamed := s.streamWindow > 0 && s.onContent == nil
+		err = s.withTimeout(func() error {
+			return s.withRetries(filePath, func() error {
+				var readErr error
+				if streamed {
+					matches, findings, readErr = s.streamContent(filePath, limit, &entry)
+				} else {
+					contents, readErr = s.readContent(filePath)
+				}
+				return readErr
+			})
+		})
+		if err != nil {
+			// keep walking, the file can be retried once the problem is fixed
+			s.log.Error().Msg(string(err.Error()))
+			s.recordFailure(filePath, err)
+			return nil
+		}
+		if !streamed {
+			contents, entry.bom = stripBOM(contents)
+			entry.generated = isGenerated(contents)
+			entry.ignored = isIgnored(contents)
+			if !entry.ignored && (!entry.generated || s.options.IncludeGenerated) {
+				matches, findings = s.matchContent(filePath, contents, 1, limit)
+			}
+		}
+		// a truncated count would be wrong for a later scan with different limits
+		if limit == 0 {
+			entry.matches = matches
+			s.index.store(filePath, entry)
+		}
+	}
+	if entry.bom {
+		s.recordBOM(filePath)
+	}
+	if entry.generated && !s.options.IncludeGenerated {
+		s.skipGenerated(filePath)
+		return nil
+	}
+	if entry.ignored {
+		s.skipIgnored(filePath)
+		return nil
+	}
+	if matches == 0 && !entry.bom {
+		s.cleanCache.remember(filePath, info)
+	}
+	if s.record(filePath, matches, findings) && s.onContent != nil {
+		s.onContent(filePath, contents)
+	}
+	return nil
+}
+
+// recordBOM reports a file starting with a byte order mark. The mark is stripped before
+// matching, as it would be glued to a marker on the first line.
+func (s *Scanner) recordBOM(filePath string) {
+	s.log.Warn().Msg("🔖 Byte order mark at the start of file → " + filePath)
+	canonical := s.canonical(filePath)
+	s.mu.Lock()
+	s.bomFiles = append(s.bomFiles, canonical)
+	s.mu.Unlock()
+}
+
+func (s *Scanner) recordFailure(filePath string, err error) {
+	s.addFailure(FailedFile{Path: filePath, Error: err.Error()})
+}
+
+// recordDirFailure records a directory to walk again on a retry.
+func (s *Scanner) recordDirFailure(dir string, err error) {
+	s.addFailure(FailedFile{Path: dir, Error: err.Error(), Dir: true})
+}
+
+func (s *Scanner) addFailure(failed FailedFile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failedFiles = append(s.failedFiles, failed)
+	s.filesFailed.Add(1)
+	s.checkpoint.fail(failed.Path)
+}
+
+func (s *Scanner) skipGenerated(filePath string) {
+	s.log.Log().Msg("🤖 Skipping generated file: " + filePath)
+	s.filesSkipped.Add(1)
+}
+
+// isIgnored reports whether the file opts out of the scan with an ignore-file directive.
+func isIgnored(content []byte) bool {
+	return bytes.Contains(content, []byte(IGNORE_FILE_DIRECTIVE))
 }
 
-func readFile(filePath string, fileName string) error {
-	file, err := os.ReadFile(filePath)
+func (s *Scanner) skipIgnored(filePath string) {
+	s.log.Log().Msg("🙈 Skipping ignored file: " + filePath)
+	s.filesIgnored.Add(1)
+}
+
+// matchContent counts the rule and analyzer matches in a file's content, starting at
+// firstLine of the file, listing the matched lines when findings are wanted.
+func (s *Scanner) matchContent(filePath string, file []byte, firstLine int, limit int) (int, []Match) {
+	contents := string(file)
+	found := []Match{}
+	onMatch := func(match Match) {
+		found = append(found, match)
+	}
+	matches, suppressed := countMatches(s.options.Rules, contents, limit, s.options.IgnoreComments, onMatch)
+	if suppressed > 0 {
+		s.log.Info().Msg("🙈 Suppressed " + strconv.Itoa(suppressed) + " matches in file → " + filePath)
+		s.matchesSuppressed.Add(int64(suppressed))
+	}
+	if len(analyzers) > 0 && (limit == 0 || matches < limit) {
+		remaining := 0
+		if limit > 0 {
+			remaining = limit - matches
+		}
+		matches += runAnalyzers(filePath, file, remaining, onMatch, s.log)
+	}
+	if len(found) == 0 {
+		return matches, nil
+	}
+	keys := lineKeys(filePath, contents)
+	for i := range found {
+		match := &found[i]
+		match.Path = filePath
+		// an analyzer may tell the key itself
+		if match.Key == "" {
+			match.Key = keys[match.Line]
+		}
+		match.Line += firstLine - 1
+		event := s.log.Info().Str("path", match.Path).Int("line", match.Line).Int("col", match.Col).Str("rule", match.Rule)
+		if match.Key != "" {
+			event = event.Str("key", match.Key)
+		}
+		if match.analyzer != "" {
+			event = event.Str("analyzer", match.analyzer)
+		}
+		event.Str("snippet", match.Snippet).Msg("Matched")
+	}
+	if s.onFinding == nil {
+		return matches, nil
+	}
+	s.resolveSourceMap(filePath, contents, found)
+	return matches, found
+}
+
+// record adds a scanned file's matches to the results. A file reached a second time,
+// through a symlink for instance, is only reported once: false is returned then.
+func (s *Scanner) record(filePath string, matches int, findings []Match) bool {
+	canonical := s.canonical(filePath)
+	s.mu.Lock()
+	if s.recorded == nil {
+		s.recorded = map[string]bool{}
+	}
+	duplicate := s.recorded[canonical]
+	s.recorded[canonical] = true
+	s.mu.Unlock()
+	if duplicate {
+		s.log.Log().Msg("❌ Skipping already scanned file: " + filePath)
+		return false
+	}
+	s.checkpoint.recordFile(filePath, matches, findings)
+
+	matched := matches > 0
+	s.countCandidate(filePath, matches)
+	if matched {
+		s.mu.Lock()
+		s.foundFiles = append(s.foundFiles, canonical)
+		if s.fileMatches == nil {
+			s.fileMatches = map[string]int{}
+		}
+		s.fileMatches[canonical] = matches
+		s.mu.Unlock()
+		s.filesMatched.Add(1)
+		total := s.matchesFound.Add(int64(matches))
+		if s.options.MaxTotalMatches > 0 && total >= int64(s.options.MaxTotalMatches) && !s.limitReached.Swap(true) {
+			s.log.Warn().Msg("✋ Stopping the scan after " + strconv.FormatInt(total, 10) + " matches")
+		}
+		if s.onMatch != nil {
+			s.onMatch(filePath)
+		}
+		if s.onFinding != nil {
+			for _, finding := range findings {
+				// reported under the same path as the found file, or its source's canonical path
+				// with the bundle's location under the found file's
+				if finding.Bundle == "" {
+					finding.Path = canonical
+				} else {
+					finding.Path = s.canonical(finding.Path)
+					finding.Bundle = canonical + strings.TrimPrefix(finding.Bundle, filePath)
+				}
+				s.onFinding(finding)
+			}
+		}
+	} else if s.onMiss != nil {
+		s.onMiss(canonical)
+	}
+	return true
+}
+
+// matchLimit returns how many more matches the next file may contribute, 0 meaning no limit.
+func (s *Scanner) matchLimit() int {
+	limit := s.options.MaxMatchesPerFile
+	if s.options.MaxTotalMatches > 0 {
+		remaining := s.options.MaxTotalMatches - int(s.matchesFound.Load())
+		if remaining < 1 {
+			s.limitReached.Store(true)
+			remaining = 1
+		}
+		if limit == 0 || remaining < limit {
+			limit = remaining
+		}
+	}
+	return limit
+}
+
+// readContent reads the whole file in chunks of the configured buffer size.
+func (s *Scanner) readContent(filePath string) ([]byte, error) {
+	f, err := s.fs.Open(filePath)
 	if err != nil {
-		logger.Error().Msg(string(err.Error()))
-		return fmt.Errorf("error reading file %s", filePath)
+		return nil, err
 	}
-	contents := string(file)
-	if strings.Contains(contents, DATA_MC_TRANSLATE) || strings.Contains(contents, MESSAGE_ID) {
-		logger.Info().Msg("Matched entry in file → " + filePath)
-		foundFiles = append(foundFiles, fileName)
+	defer f.Close()
+
+	content := []byte{}
+	chunk := make([]byte, s.options.BufferSize)
+	for {
+		n, err := f.Read(chunk)
+		content = append(content, chunk[:n]...)
+		s.bytesRead.Add(int64(n))
+		s.throttle.wait(n)
+		if err == io.EOF {
+			return content, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// countCandidate records the file and its matches against the coverage of its top-level
+// directory, and of the policy directories holding it.
+func (s *Scanner) countCandidate(filePath string, matches int) {
+	directory, rel := ".", ""
+	if relPath, err := filepath.Rel(s.root, filePath); err == nil {
+		rel = filepath.ToSlash(relPath)
+		if parts := strings.SplitN(rel, "/", 2); len(parts) == 2 {
+			directory = parts[0]
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rel != "" {
+		s.countPolicies(rel, matches)
+	}
+	if s.coverage == nil {
+		s.coverage = map[string]*Coverage{}
+	}
+	c, ok := s.coverage[directory]
+	if !ok {
+		c = &Coverage{Directory: directory}
+		s.coverage[directory] = c
+	}
+	c.Candidates++
+	if matches > 0 {
+		c.Matched++
+		c.Matches += matches
+	}
+}
+
+// Coverage returns the per top-level directory coverage, in natural order of directory.
+func (s *Scanner) Coverage() []Coverage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	coverage := []Coverage{}
+	for _, c := range s.coverage {
+		coverage = append(coverage, *c)
+	}
+	sort.Slice(coverage, func(i, j int) bool { return naturalLess(coverage[i].Directory, coverage[j].Directory) })
+	return coverage
+}
+
+// scan walks the tree at location, a local path or a remote URL, handing candidate files to the configured number of workers.
+func (s *Scanner) scan(location string) (err error) {
+	fsys, root, release, err := openLocation(location)
+	if err != nil {
+		s.log.Error().Msg(err.Error())
+		return err
+	}
+	defer release()
+	s.fs = fsys
+	s.root = root
+	s.log = scanLogger(root, s.options)
+	s.logMemoryBudget()
+	s.tuneForNetwork()
+	s.excludeFixtures = s.excludesFixtures()
+	if s.options.Timeout > 0 {
+		s.deadline = time.Now().Add(s.options.Timeout)
+	}
+	defer func() { sortPaths(s.foundFiles, s.options.Sort) }()
+	if s.options.Checkpoint != "" {
+		var previous *Checkpoint
+		if s.checkpoint, previous, err = openCheckpoint(s.options.Checkpoint, root, s.options, s.log); err != nil {
+			return err
+		}
+		if previous != nil {
+			s.resume(previous)
+		}
+		defer s.finishCheckpoint(&err)
+	}
+	// the content extraction needs and the analyzers' matches aren't cached
+	if s.options.CleanCache && s.onContent == nil && len(analyzers) == 0 {
+		if s.cleanCache, err = loadCleanCache(root, s.options, s.log); err != nil {
+			s.log.Error().Msg(err.Error())
+		}
+	}
+	if s.options.Workers <= 1 {
+		if err = s.walkDir(root); err != nil {
+			return err
+		}
+		return s.cleanCache.save()
+	}
+
+	s.files = make(chan candidateFile, s.options.Workers)
+	var wg sync.WaitGroup
+	for i := 0; i < s.options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range s.files {
+				if err := s.process(file); err != nil {
+					s.fail(err)
+				}
+			}
+		}()
+	}
+	err = s.walkDir(root)
+	close(s.files)
+	wg.Wait()
+	if err != nil {
+		return err
 	}
+	if err = s.failure(); err != nil {
+		return err
+	}
+	return s.cleanCache.save()
+}
+
+// rescan reads the failed files of location again, and walks the failed directories again,
+// as a retry.
+func (s *Scanner) rescan(location string, failed []FailedFile) error {
+	fsys, root, release, err := openLocation(location)
+	if err != nil {
+		s.log.Error().Msg(err.Error())
+		return err
+	}
+	defer release()
+	s.fs = fsys
+	s.root = root
+	s.log = scanLogger(root, s.options)
+	s.excludeFixtures = s.excludesFixtures()
+	for _, f := range failed {
+		if f.Dir {
+			s.log.Info().Msg("🔁 Retrying folder → " + f.Path)
+			// walkDir records the folders failing again, the root aside
+			if err := s.walkDir(f.Path); err != nil && f.Path == s.root {
+				s.recordDirFailure(f.Path, err)
+			}
+			continue
+		}
+		s.log.Info().Msg("🔁 Retrying file → " + f.Path)
+		if err := s.process(candidateFile{path: f.Path, archive: s.options.Archives && isArchive(f.Path)}); err != nil {
+			return err
+		}
+	}
+	sortPaths(s.foundFiles, s.options.Sort)
 	return nil
 }
 
-func walkDir(dir string) error {
-	entries, err := os.ReadDir(dir)
+// visitFile processes the candidate file, or queues it when workers are running.
+func (s *Scanner) visitFile(file candidateFile) error {
+	if s.checkpoint.skipFile(file.path) {
+		return nil
+	}
+	s.checkpoint.start(file.path)
+	if s.files == nil {
+		return s.process(file)
+	}
+	if err := s.failure(); err != nil {
+		return err
+	}
+	s.files <- file
+	return nil
+}
+
+func (s *Scanner) process(file candidateFile) error {
+	var err error
+	if file.archive {
+		err = s.scanArchive(file.path)
+	} else {
+		err = s.readFile(file)
+	}
+	if err == nil && !s.limitReached.Load() && !s.timedOut.Load() {
+		s.checkpoint.finish(s, file.path)
+	}
+	return err
+}
+
+// fail records the first error hit by a worker.
+func (s *Scanner) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *Scanner) failure() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// isCandidate reports whether the file is one where the content is supposed to be translated:
+// for angularjs code we are looking at .HTML files and for react components we are looking at .JS files for the content.
+// vue single file components hold both, a template and a script.
+// test files are also .JS files, but they have _spec in their names, which is why we are not considering them at this point in time.
+func isCandidate(filePath string) bool {
+	fileExtension := filepath.Ext(filePath)
+	return (fileExtension == JS_EXT || fileExtension == HTML_EXT || fileExtension == VUE_EXT) && !strings.Contains(filePath, TEST_FILE_STRING)
+}
+
+func (s *Scanner) walkDir(dir string) error {
+	if s.checkpoint.skipDir(dir) {
+		s.log.Log().Msg("📍 Skipping folder walked through before the checkpoint: " + dir)
+		return nil
+	}
+	var entries []fs.DirEntry
+	fresh := true
+	err := s.withTimeout(func() error {
+		var readErr error
+		entries, fresh, readErr = s.index.readDir(s.fs, dir)
+		return readErr
+	})
 	if err != nil {
-		logger.Error().Msg(string(err.Error()))
+		s.log.Error().Msg(string(err.Error()))
+		if dir != s.root {
+			s.recordDirFailure(dir, err)
+		}
 		return fmt.Errorf("error reading directory: %v", err)
 	}
+	if !fresh {
+		s.listingsReused.Add(1)
+	}
 	for _, entry := range entries {
+		if s.limitReached.Load() || s.expired() {
+			return nil
+		}
 		if entry.Name() == NODE_MODULES_FOLDER || entry.Name() == BUILD_FOLDER || entry.Name() == PUBLIC_FOLDER {
-			logger.Log().Msg("❌ Skipping folder: " + entry.Name())
+			s.log.Log().Msg("❌ Skipping folder: " + entry.Name())
+			continue
+		}
+		if !s.options.Hidden && strings.HasPrefix(entry.Name(), ".") {
+			s.log.Log().Msg("❌ Skipping hidden entry: " + entry.Name())
+			continue
+		}
+		if s.excludeFixtures && isFixture(entry.Name(), entry.IsDir()) {
+			s.log.Log().Msg("❌ Skipping fixture: " + entry.Name())
 			continue
 		}
 		// log.Println("Current Entry : " + entry.Name())
-		if entry.IsDir() {
-			subdir := path.Join(dir, entry.Name())
-			walkDir(subdir)
+		if entry.IsDir() || s.followsJunction(dir, entry) {
+			subdir := s.fs.Join(dir, entry.Name())
+			s.walkDir(subdir)
 		} else {
-			filePath := path.Join(dir, entry.Name())
-			fileExtension := path.Ext(filePath)
-			// we only look at the files where the content is supposed to be translated
-			// for angularjs code we are looking at .HTML files and for react components we are looking at .JS files for the content
-			// test files are also .JS files, but they have _spec in their names, which is why we are not considering them at this point in time.
-			if (fileExtension == JS_EXT || fileExtension == HTML_EXT) && !strings.Contains(filePath, TEST_FILE_STRING) {
+			filePath := s.fs.Join(dir, entry.Name())
+			if isCandidate(filePath) {
 				// log.Println("Reading file → " + filePath)
-				err := readFile(filePath, entry.Name())
+				file := candidateFile{path: filePath}
+				if fresh {
+					file.entry = entry
+				}
+				err := s.visitFile(file)
+				if err != nil {
+					return err
+				}
+			} else if s.options.Archives && isArchive(filePath) {
+				err := s.visitFile(candidateFile{path: filePath, archive: true})
 				if err != nil {
 					return err
 				}
 			}
 		}
 	}
-
+	if !s.limitReached.Load() && !s.timedOut.Load() {
+		s.checkpoint.dirDone(dir)
+	}
 	return nil
 
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "daemon":
+			runDaemon(os.Args[2:])
+			return
+		case "query":
+			runQuery(os.Args[2:])
+			return
+		case "scan":
+			runScan(os.Args[2:])
+			return
+		case "trend":
+			runTrend(os.Args[2:])
+			return
+		case "catalog":
+			runCatalog(os.Args[2:])
+			return
+		case "bench":
+			runBench(os.Args[2:])
+			return
+		case "lsp":
+			runLSP(os.Args[2:])
+			return
+		case "rpc":
+			runRPC(os.Args[2:])
+			return
+		case "logs":
+			runLogs(os.Args[2:])
+			return
+		}
+	}
+
+	var analyzerCommands stringList
+	flag.Var(&analyzerCommands, "analyzer", "command starting an external gRPC analyzer (repeatable)")
+	profiles := addProfileFlags(flag.CommandLine)
+	options := addScanFlags(flag.CommandLine)
+	configPath := addConfigFlag(flag.CommandLine)
+	addNoColorFlag(flag.CommandLine)
+	exportPath := flag.String("export", "", "write the results of every scan of the session to this JSON file on exit")
+	mouse := flag.Bool("mouse", false, "scroll and click the results list with the mouse (runs full screen, terminal text selection needs shift)")
+	sessionPath := flag.String("session", defaultSessionPath(), "file the TUI session is saved to as it changes")
+	resume := flag.Bool("resume", false, "continue the session saved in the -session file")
+	bookmark := flag.String("bookmark", "", "scan the directory bookmarked under this name in the config right away")
+	notifyAfter := flag.Duration("notify-after", DEFAULT_NOTIFY_AFTER, "send a desktop notification when a scan takes at least this long (0 disables)")
+	flag.Parse()
+
+	scanRun = true
 	setupLogger()
+	if err := applyConfig(*configPath, options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := applyTheme(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if plainMode || noColorRequested() {
+		enablePlainMode()
+	}
+	startLocation := ""
+	if *bookmark != "" {
+		bookmarkPath, err := findBookmark(config.Bookmarks, *bookmark)
+		if err == nil {
+			err = validateLocation(bookmarkPath)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		startLocation = bookmarkPath
+	}
+	stopProfiling, err := profiles.start()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopProfiling()
+	if err := startAnalyzers(analyzerCommands); err != nil {
+		stopProfiling()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopAnalyzers()
+
 	generateWelcomeHeader()
 
-	t := textinput.NewModel()
+	t := textinput.New()
 	t.Focus()
 
-	s := spinner.NewModel()
+	s := spinner.New()
 	s.Spinner = spinner.Dot
+	if plainMode {
+		s.Spinner = spinner.Line
+	}
+	s.Style = styles.Spinner
+
+	keys.Bookmarks.SetEnabled(len(config.Bookmarks) > 0)
+
+	marks, err := loadMarks()
+	if err != nil {
+		logger.Error().Msg(err.Error())
+		marks = Marks{}
+	}
+
+	recent, err := loadRecentDirectories()
+	if err != nil {
+		logger.Error().Msg(err.Error())
+	}
 
 	initialModel := Model{
 		textInput: t,
 		spinner:   s,
-		typing:    true,
+		help:      newHelp(),
+		typing:    startLocation == "",
+		options:   *options,
+
+		sessionFiles: map[string]bool{},
+		recent:       recent,
+		recentIndex:  -1,
+		marks:        marks,
+
+		choosing:      startLocation == "" && len(config.Bookmarks) > 0,
+		loading:       startLocation != "",
+		startLocation: startLocation,
+		sessionPath:   *sessionPath,
+		notifyAfter:   *notifyAfter,
+	}
+	if *resume {
+		initialModel, err = initialModel.resumeSession()
+		if err != nil {
+			stopAnalyzers()
+			stopProfiling()
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	programOptions := []tea.ProgramOption{}
+	if *mouse {
+		// mouse coordinates are only meaningful relative to the screen's top in full screen mode
+		programOptions = append(programOptions, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	}
-	err := tea.NewProgram(initialModel).Start()
+	finalModel, err := tea.NewProgram(initialModel, programOptions...).Run()
 	if err != nil {
+		stopAnalyzers()
+		stopProfiling()
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	finalModel.(Model).autosave()
+	if *exportPath != "" {
+		if err := exportSession(*exportPath, finalModel.(Model).history, options.Sort); err != nil {
+			logger.Error().Msg(err.Error())
+			stopAnalyzers()
+			stopProfiling()
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
 }
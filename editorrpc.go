@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+const RPC_SCAN_FAILED = -32000
+
+// editorServer is the backend of an editor extension, serving scanFile, scanWorkspace and
+// getResults. The workspace keeps an index, so scanning it again only reads the files
+// changed since, and the buffers not yet saved are matched from the text the editor sends,
+// again only once it changed.
+type editorServer struct {
+	out     *bufio.Writer
+	options ScanOptions
+	root    string
+	index   *Index
+	// results are the matches of the files as saved, by canonical path
+	results map[string][]Match
+	// buffers are the unsaved buffers, whose matches override the saved file's
+	buffers  map[string]editorBuffer
+	shutdown bool
+}
+
+type editorBuffer struct {
+	text    string
+	matches []Match
+}
+
+type editorParams struct {
+	Path string `json:"path"`
+	// Text is the content of an unsaved buffer; without it the file is read from disk,
+	// as it is once the buffer is saved or reverted.
+	Text *string `json:"text"`
+}
+
+// FileResult is the matches of a file, Dirty when they are those of its unsaved buffer.
+type FileResult struct {
+	Path    string  `json:"path"`
+	Dirty   bool    `json:"dirty"`
+	Matches []Match `json:"matches"`
+}
+
+// WorkspaceResult sums up a workspace scan.
+type WorkspaceResult struct {
+	Root         string        `json:"root"`
+	Duration     time.Duration `json:"duration"`
+	FilesScanned int64         `json:"files_scanned"`
+	FilesMatched int64         `json:"files_matched"`
+	Matches      int64         `json:"matches"`
+}
+
+// scanText matches the content of a file the way the scan does, nothing for the files the
+// scan skips.
+func (es *editorServer) scanText(filePath string, contents []byte) []Match {
+	matches := []Match{}
+	if !isCandidate(filePath) {
+		return matches
+	}
+	contents, _ = stripBOM(contents)
+	if isIgnored(contents) || (isGenerated(contents) && !es.options.IncludeGenerated) {
+		return matches
+	}
+	scanner := NewScanner(es.options)
+	scanner.fs, scanner.root = localFileSystem{}, es.root
+	scanner.onFinding = func(Match) {}
+	if _, found := scanner.matchContent(filePath, contents, 1, es.options.MaxMatchesPerFile); found != nil {
+		matches = found
+	}
+	return matches
+}
+
+// scanFile matches an unsaved buffer, or the file on disk, and remembers the result.
+func (es *editorServer) scanFile(params editorParams) (FileResult, error) {
+	filePath := canonicalPath(localFileSystem{}, params.Path)
+	if params.Text == nil {
+		delete(es.buffers, filePath)
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			return FileResult{}, fmt.Errorf("error reading %s: %v", filePath, err)
+		}
+		es.results[filePath] = es.scanText(filePath, contents)
+		return FileResult{Path: filePath, Matches: es.results[filePath]}, nil
+	}
+	buffer, ok := es.buffers[filePath]
+	if !ok || buffer.text != *params.Text {
+		buffer = editorBuffer{text: *params.Text, matches: es.scanText(filePath, []byte(*params.Text))}
+		es.buffers[filePath] = buffer
+	}
+	return FileResult{Path: filePath, Dirty: true, Matches: buffer.matches}, nil
+}
+
+// scanWorkspace scans a root, from its index when it was scanned before. The files read
+// from the index keep the matches of the scan that read them.
+func (es *editorServer) scanWorkspace(params editorParams) (WorkspaceResult, error) {
+	root := canonicalPath(localFileSystem{}, params.Path)
+	if root != es.root {
+		es.root, es.index = root, NewIndex()
+	}
+	started := time.Now()
+	scanner := NewScanner(es.options)
+	scanner.index = es.index
+	matches := collectMatches(scanner)
+	if err := scanner.scan(root); err != nil {
+		return WorkspaceResult{}, err
+	}
+	fresh := map[string][]Match{}
+	for _, match := range *matches {
+		fresh[match.Path] = append(fresh[match.Path], match)
+	}
+	results := map[string][]Match{}
+	for _, filePath := range scanner.foundFiles {
+		if found, ok := fresh[filePath]; ok {
+			results[filePath] = found
+		} else {
+			results[filePath] = es.results[filePath]
+		}
+	}
+	es.results = results
+	logger.Info().Msg(fmt.Sprintf("🧩 Scanned workspace %s, %d matched files read again", root, len(fresh)))
+	return WorkspaceResult{
+		Root:         root,
+		Duration:     time.Since(started),
+		FilesScanned: scanner.filesScanned.Load(),
+		FilesMatched: scanner.filesMatched.Load(),
+		Matches:      scanner.matchesFound.Load(),
+	}, nil
+}
+
+// fileResult is the matches of a file, from its unsaved buffer if there's one.
+func (es *editorServer) fileResult(filePath string) FileResult {
+	if buffer, ok := es.buffers[filePath]; ok {
+		return FileResult{Path: filePath, Dirty: true, Matches: buffer.matches}
+	}
+	matches := es.results[filePath]
+	if matches == nil {
+		matches = []Match{}
+	}
+	return FileResult{Path: filePath, Matches: matches}
+}
+
+// getResults lists the matched files with their matches, or the matches of one file.
+func (es *editorServer) getResults(params editorParams) []FileResult {
+	if params.Path != "" {
+		return []FileResult{es.fileResult(canonicalPath(localFileSystem{}, params.Path))}
+	}
+	paths := []string{}
+	for filePath := range es.results {
+		if _, ok := es.buffers[filePath]; !ok && len(es.results[filePath]) > 0 {
+			paths = append(paths, filePath)
+		}
+	}
+	for filePath, buffer := range es.buffers {
+		if len(buffer.matches) > 0 {
+			paths = append(paths, filePath)
+		}
+	}
+	sortPaths(paths, es.options.Sort)
+	files := make([]FileResult, 0, len(paths))
+	for _, filePath := range paths {
+		files = append(files, es.fileResult(filePath))
+	}
+	return files
+}
+
+// handle answers a request of the editor extension.
+func (es *editorServer) handle(message rpcMessage) (interface{}, *rpcError) {
+	var params editorParams
+	if len(message.Params) > 0 {
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			return nil, &rpcError{Code: RPC_INVALID_PARAMS, Message: err.Error()}
+		}
+	}
+	switch message.Method {
+	case "initialize":
+		return map[string]string{"name": "dirwalker", "version": VERSION}, nil
+	case "shutdown":
+		es.shutdown = true
+		return nil, nil
+	case "getResults":
+		return es.getResults(params), nil
+	}
+	if message.Method != "scanFile" && message.Method != "scanWorkspace" {
+		return nil, &rpcError{Code: RPC_METHOD_NOT_FOUND, Message: "unknown method " + message.Method}
+	}
+	if params.Path == "" {
+		return nil, &rpcError{Code: RPC_INVALID_PARAMS, Message: message.Method + " needs a path"}
+	}
+	var result interface{}
+	var err error
+	if message.Method == "scanFile" {
+		result, err = es.scanFile(params)
+	} else {
+		result, err = es.scanWorkspace(params)
+	}
+	if err != nil {
+		logger.Error().Msg(err.Error())
+		return nil, &rpcError{Code: RPC_SCAN_FAILED, Message: err.Error()}
+	}
+	return result, nil
+}
+
+// runRPC serves JSON-RPC on the standard input and output, framed as LSP is, for editor
+// extensions to scan the workspace and the buffers being edited.
+func runRPC(args []string) {
+	flags := flag.NewFlagSet("rpc", flag.ExitOnError)
+	options := addScanFlags(flags)
+	configPath := addConfigFlag(flags)
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker rpc [-config file]")
+		os.Exit(2)
+	}
+
+	setupLogger()
+	if err := applyConfig(*configPath, options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	es := &editorServer{
+		out:     bufio.NewWriter(os.Stdout),
+		options: *options,
+		results: map[string][]Match{},
+		buffers: map[string]editorBuffer{},
+	}
+	logger.Info().Msg("🧩 Editor backend started")
+	if err := serveRPC(os.Stdin, es.out, es.handle); err != nil {
+		logger.Error().Msg(err.Error())
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if !es.shutdown {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DEFAULT_SMTP_PORT = 587
+const SMTP_PASSWORD_VARIABLE = "DIRWALKER_SMTP_PASSWORD"
+
+// SMTPSettings is the mail server reports are sent through. The password can be left out
+// of the config and given in the DIRWALKER_SMTP_PASSWORD environment variable instead.
+type SMTPSettings struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// parseRecipients splits the comma separated addresses given to -email-report.
+func parseRecipients(value string) []string {
+	recipients := []string{}
+	for _, address := range strings.Split(value, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			recipients = append(recipients, address)
+		}
+	}
+	return recipients
+}
+
+// emailReport sends the report to the recipients, as Markdown with an HTML alternative.
+func emailReport(settings SMTPSettings, recipients []string, report Report) error {
+	if settings.Host == "" || settings.From == "" {
+		return fmt.Errorf("error sending report: smtp host and from must be set in the config")
+	}
+	if settings.Port == 0 {
+		settings.Port = DEFAULT_SMTP_PORT
+	}
+	if password := os.Getenv(SMTP_PASSWORD_VARIABLE); password != "" {
+		settings.Password = password
+	}
+	// the envelope takes the bare addresses, the headers their display names too
+	from, err := mail.ParseAddress(settings.From)
+	if err != nil {
+		return fmt.Errorf("error sending report: invalid smtp from %q: %v", settings.From, err)
+	}
+	to := []string{}
+	envelope := []string{}
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("error sending report: invalid recipient %q: %v", recipient, err)
+		}
+		to = append(to, address.String())
+		envelope = append(envelope, address.Address)
+	}
+	html, err := report.html()
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", report.markdown()},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return fmt.Errorf("error composing report email: %v", err)
+		}
+		w.Write([]byte(part.content))
+	}
+	parts.Close()
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from.String())
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", report.Title()))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	message.Write(body.Bytes())
+
+	var auth smtp.Auth
+	if settings.Username != "" {
+		auth = smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)
+	}
+	address := net.JoinHostPort(settings.Host, strconv.Itoa(settings.Port))
+	if err := smtp.SendMail(address, auth, from.Address, envelope, message.Bytes()); err != nil {
+		return fmt.Errorf("error sending report: %v", err)
+	}
+	logger.Info().Msg("📧 Sent report to " + strings.Join(recipients, ", "))
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const PROBLEM_ENCODING = "encoding"
+
+// UTF8_BOM is the byte order mark some editors write at the start of UTF-8 files.
+const UTF8_BOM = "\xef\xbb\xbf"
+
+// CP1252_BYTES are the characters Windows-1252 has in place of the C1 controls, from the
+// bytes 0x80 to 0x9f: UTF-8 read as Windows-1252 turns into them.
+var CP1252_BYTES = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// latinByte is the byte a character is in Latin-1 or Windows-1252.
+func latinByte(r rune) (byte, bool) {
+	if b, ok := CP1252_BYTES[r]; ok {
+		return b, true
+	}
+	return byte(r), r < 0x100
+}
+
+// doubleEncoded finds UTF-8 that was decoded as Latin-1 or Windows-1252 and encoded again,
+// like "Ã©" for "é", returning the garbled text and what it was.
+func doubleEncoded(text string) (string, string, bool) {
+	runes := []rune(text)
+	for i, r := range runes {
+		lead, ok := latinByte(r)
+		if !ok || lead < 0xc2 || lead > 0xf4 {
+			continue
+		}
+		size := 2
+		if lead >= 0xf0 {
+			size = 4
+		} else if lead >= 0xe0 {
+			size = 3
+		}
+		if i+size > len(runes) {
+			continue
+		}
+		encoded := []byte{lead}
+		for _, next := range runes[i+1 : i+size] {
+			if b, ok := latinByte(next); ok && b >= 0x80 && b <= 0xbf {
+				encoded = append(encoded, b)
+			}
+		}
+		if len(encoded) == size && utf8.Valid(encoded) {
+			return string(runes[i : i+size]), string(encoded), true
+		}
+	}
+	return "", "", false
+}
+
+// stripBOM removes a byte order mark from the start of contents, telling whether there was one.
+func stripBOM(contents []byte) ([]byte, bool) {
+	if bytes.HasPrefix(contents, []byte(UTF8_BOM)) {
+		return contents[len(UTF8_BOM):], true
+	}
+	return contents, false
+}
+
+// checkEncoding reports the texts broken by a wrong encoding: replacement characters left
+// by undecodable bytes, double-encoded UTF-8 and byte order marks in the middle of them,
+// and a byte order mark starting the file.
+func checkEncoding(c *Catalog) []CatalogProblem {
+	problems := []CatalogProblem{}
+	if c.bom {
+		problems = append(problems, CatalogProblem{Path: c.Path, Line: 1, Kind: PROBLEM_ENCODING, Message: "starts with a byte order mark"})
+	}
+	for _, key := range c.Keys {
+		message := c.Messages[key]
+		report := func(text string) {
+			problems = append(problems, CatalogProblem{Path: c.Path, Line: message.Line, Kind: PROBLEM_ENCODING, Key: key, Message: text})
+		}
+		if strings.ContainsRune(message.Text, utf8.RuneError) {
+			report("has the replacement character U+FFFD, for bytes that weren't decodable")
+		}
+		if garbled, original, ok := doubleEncoded(message.Text); ok {
+			report(fmt.Sprintf("has %q, %q encoded twice", garbled, original))
+		}
+		if strings.Contains(message.Text, UTF8_BOM) {
+			report("has a byte order mark U+FEFF in it")
+		}
+	}
+	return problems
+}
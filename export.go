@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Catalogs can be exported but not read in these formats, for translation tools.
+const CATALOG_XLIFF = "xliff"
+const CATALOG_PO = "po"
+const CATALOG_POT = "pot"
+
+// exportFormat tells the format a catalog is written in from the file extension.
+func exportFormat(catalogPath string) string {
+	switch strings.ToLower(filepath.Ext(catalogPath)) {
+	case ".xlf", ".xliff":
+		return CATALOG_XLIFF
+	case ".po":
+		return CATALOG_PO
+	case ".pot":
+		return CATALOG_POT
+	}
+	return catalogFormat(catalogPath)
+}
+
+func xmlEscape(s string) string {
+	var out bytes.Buffer
+	xml.EscapeText(&out, []byte(s))
+	return out.String()
+}
+
+// encodeXLIFF writes an XLIFF 1.2 file with a unit per message, its description as the
+// note for the translator and where it was extracted from as its context.
+func (c *Catalog) encodeXLIFF() []byte {
+	var out strings.Builder
+	out.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	out.WriteString(`<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">` + "\n")
+	out.WriteString(`  <file source-language="` + xmlEscape(c.Locale) + `" datatype="plaintext" original="messages">` + "\n")
+	out.WriteString("    <body>\n")
+	for _, key := range c.Keys {
+		message := c.Messages[key]
+		out.WriteString(`      <trans-unit id="` + xmlEscape(key) + `">` + "\n")
+		out.WriteString("        <source>" + xmlEscape(message.Text) + "</source>\n")
+		if message.Description != "" {
+			out.WriteString(`        <note from="developer">` + xmlEscape(message.Description) + "</note>\n")
+		}
+		if message.origin != "" {
+			out.WriteString(`        <context-group purpose="location"><context context-type="sourcefile">` + xmlEscape(message.origin) + "</context></context-group>\n")
+		}
+		out.WriteString("      </trans-unit>\n")
+	}
+	out.WriteString("    </body>\n  </file>\n</xliff>\n")
+	return []byte(out.String())
+}
+
+// poString quotes s for a PO file, splitting it after newlines.
+func poString(s string) string {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		return strconv.Quote(s)
+	}
+	lines := strings.SplitAfter(s, "\n")
+	parts := []string{`""`}
+	for _, line := range lines {
+		if line != "" {
+			parts = append(parts, strconv.Quote(line))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// encodePO writes a gettext catalog: the key is the msgctxt of every entry, the description
+// an extracted comment (#.) and the origin a reference (#:). A template (.pot) leaves the
+// msgstr empty, a PO file holds the text of the catalog's locale.
+func (c *Catalog) encodePO(template bool) []byte {
+	var out strings.Builder
+	out.WriteString("msgid \"\"\nmsgstr \"\"\n")
+	out.WriteString(`"Content-Type: text/plain; charset=UTF-8\n"` + "\n")
+	if !template {
+		out.WriteString(`"Language: ` + c.Locale + `\n"` + "\n")
+	}
+	for _, key := range c.Keys {
+		message := c.Messages[key]
+		out.WriteString("\n")
+		for _, line := range strings.Split(message.Description, "\n") {
+			if line != "" {
+				out.WriteString("#. " + line + "\n")
+			}
+		}
+		if message.origin != "" {
+			out.WriteString("#: " + message.origin + "\n")
+		}
+		out.WriteString("msgctxt " + poString(key) + "\n")
+		out.WriteString("msgid " + poString(message.Text) + "\n")
+		if template {
+			out.WriteString("msgstr \"\"\n")
+		} else {
+			out.WriteString("msgstr " + poString(message.Text) + "\n")
+		}
+	}
+	return []byte(out.String())
+}
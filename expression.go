@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// exprNode is a parsed rule expression such as `"<Message" AND NOT "defaultMessage"`.
+type exprNode interface {
+	eval(rule Rule, line string, lowered *string) bool
+}
+
+type termNode string
+
+type notNode struct {
+	operand exprNode
+}
+
+type andNode struct {
+	left, right exprNode
+}
+
+type orNode struct {
+	left, right exprNode
+}
+
+// a term matches when its text is found in the line, honoring the rule's case and token options
+func (t termNode) eval(rule Rule, line string, lowered *string) bool {
+	rule.Pattern = string(t)
+	found, _ := rule.occurrences(line, lowered, 1, nil)
+	return found > 0
+}
+
+func (n notNode) eval(rule Rule, line string, lowered *string) bool {
+	return !n.operand.eval(rule, line, lowered)
+}
+
+func (n andNode) eval(rule Rule, line string, lowered *string) bool {
+	return n.left.eval(rule, line, lowered) && n.right.eval(rule, line, lowered)
+}
+
+func (n orNode) eval(rule Rule, line string, lowered *string) bool {
+	return n.left.eval(rule, line, lowered) || n.right.eval(rule, line, lowered)
+}
+
+// parseExpression parses the small rule expression syntax:
+//
+//	expr    = and { "OR" and }
+//	and     = unary { "AND" unary }
+//	unary   = "NOT" unary | "(" expr ")" | string
+//	string  = "..." | '...' | `...`
+//
+// Operators are case-insensitive; AND binds tighter than OR.
+func parseExpression(expression string) (exprNode, error) {
+	tokens, err := tokenizeExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in expression %q", p.tokens[p.pos].text, expression)
+	}
+	return node, nil
+}
+
+type exprToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizeExpression(expression string) ([]exprToken, error) {
+	tokens := []exprToken{}
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, exprToken{text: string(c)})
+			i++
+		case c == '"' || c == '\'' || c == '`':
+			end := strings.IndexByte(expression[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in expression %q", expression)
+			}
+			tokens = append(tokens, exprToken{text: expression[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		default:
+			end := i
+			for end < len(expression) && !unicode.IsSpace(rune(expression[end])) && expression[end] != '(' && expression[end] != ')' {
+				end++
+			}
+			tokens = append(tokens, exprToken{text: expression[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+// accept consumes the next token if it is the given (unquoted) operator.
+func (p *exprParser) accept(operator string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, operator) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.accept("NOT") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return node, nil
+	}
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	token := p.tokens[p.pos]
+	if !token.quoted {
+		return nil, fmt.Errorf("expected a quoted string, found %q", token.text)
+	}
+	if token.text == "" {
+		return nil, fmt.Errorf("empty string in expression")
+	}
+	p.pos++
+	return termNode(token.text), nil
+}
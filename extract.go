@@ -0,0 +1,412 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const PROBLEM_KEY_STYLE = "key-style"
+
+// ExtractedMessage is a message found in the source code, with its default text.
+type ExtractedMessage struct {
+	ID          string `json:"id"`
+	Text        string `json:"text"`
+	Description string `json:"description,omitempty"`
+	Path        string `json:"path"`
+	Line        int    `json:"line"`
+}
+
+// EXTRACTORS pull the messages out of a file's content.
+var EXTRACTORS = []func(filePath string, contents string) []ExtractedMessage{
+	extractMessageComponents,
+	extractI18next,
+	extractReactIntl,
+	extractLingui,
+	extractVueI18n,
+}
+
+// TRANSLATOR_COMMENT starts a comment for the translators, like "// i18n: shown on the
+// checkout button", on the line of a message or right above it.
+const TRANSLATOR_COMMENT = "i18n:"
+
+// lineKeys maps the lines of a file to the first message key the extractors read on them.
+func lineKeys(filePath string, contents string) map[int]string {
+	keys := map[int]string{}
+	for _, extractor := range EXTRACTORS {
+		for _, message := range extractor(filePath, contents) {
+			if _, ok := keys[message.Line]; !ok {
+				keys[message.Line] = message.ID
+			}
+		}
+	}
+	return keys
+}
+
+func extractMessages(filePath string, contents string) []ExtractedMessage {
+	messages := []ExtractedMessage{}
+	for _, extractor := range EXTRACTORS {
+		messages = append(messages, extractor(filePath, contents)...)
+	}
+	var lines []string
+	for i, message := range messages {
+		if message.Description != "" || !strings.Contains(contents, TRANSLATOR_COMMENT) {
+			continue
+		}
+		if lines == nil {
+			lines = strings.Split(contents, "\n")
+		}
+		messages[i].Description = translatorComment(lines, message.Line)
+	}
+	return messages
+}
+
+// translatorComment finds the i18n: comment of the message at line (from 1): at the end of
+// that line, or in the comment lines right above it, joined.
+func translatorComment(lines []string, line int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	if comment, ok := commentText(lines[line-1]); ok {
+		return comment
+	}
+	comments := []string{}
+	for i := line - 2; i >= 0; i-- {
+		comment, ok := commentText(lines[i])
+		if !ok {
+			break
+		}
+		comments = append([]string{comment}, comments...)
+	}
+	return strings.Join(comments, "\n")
+}
+
+// commentText returns the text after "i18n:" in a //, /* */, {/* */} or <!-- --> comment.
+func commentText(line string) (string, bool) {
+	i := strings.Index(line, TRANSLATOR_COMMENT)
+	if i < 0 {
+		return "", false
+	}
+	before := strings.TrimRight(line[:i], " \t")
+	if !strings.HasSuffix(before, "//") && !strings.HasSuffix(before, "/*") && !strings.HasSuffix(before, "<!--") && !strings.HasSuffix(before, "*") {
+		return "", false
+	}
+	text := line[i+len(TRANSLATOR_COMMENT):]
+	for _, end := range []string{"*/", "-->"} {
+		if j := strings.Index(text, end); j >= 0 {
+			text = text[:j]
+		}
+	}
+	return strings.TrimSpace(text), true
+}
+
+func lineAt(contents string, offset int) int {
+	return strings.Count(contents[:offset], "\n") + 1
+}
+
+// MESSAGE_COMPONENTS take their message as id, defaultMessage and description props.
+var MESSAGE_COMPONENTS = []string{"Message", "FormattedMessage", "FormattedHTMLMessage"}
+
+// extractMessageComponents reads the <Message id="..." defaultMessage="..."> elements, and
+// react-intl's <FormattedMessage>.
+func extractMessageComponents(filePath string, contents string) []ExtractedMessage {
+	messages := []ExtractedMessage{}
+	for _, component := range MESSAGE_COMPONENTS {
+		for _, start := range elementStarts(contents, component) {
+			attributes := jsxAttributes(contents, start)
+			if attributes["id"] == "" {
+				continue
+			}
+			messages = append(messages, ExtractedMessage{
+				ID:          attributes["id"],
+				Text:        attributes["defaultMessage"],
+				Description: attributes["description"],
+				Path:        filePath,
+				Line:        lineAt(contents, start),
+			})
+		}
+	}
+	return messages
+}
+
+// elementStarts lists the offsets just after the name of every <name element.
+func elementStarts(contents string, name string) []int {
+	starts := []int{}
+	tag := "<" + name
+	for offset := 0; ; {
+		i := strings.Index(contents[offset:], tag)
+		if i < 0 {
+			return starts
+		}
+		offset += i + len(tag)
+		if offset < len(contents) && !isTokenChar(contents[offset]) {
+			starts = append(starts, offset)
+		}
+	}
+}
+
+// jsxAttributes reads the attributes of the element whose name ends at start, up to the end
+// of its opening tag. Quoted values and string literals in braces ({"text"}, {'text'} and
+// template literals without substitutions) are kept; other expressions are left out.
+func jsxAttributes(contents string, start int) map[string]string {
+	attributes := map[string]string{}
+	i := start
+	for i < len(contents) {
+		for i < len(contents) && strings.IndexByte(" \t\r\n", contents[i]) >= 0 {
+			i++
+		}
+		if i >= len(contents) || contents[i] == '>' || contents[i] == '/' {
+			return attributes
+		}
+		if contents[i] == '{' {
+			// a spread, {...props}
+			_, i = jsxExpression(contents, i)
+			continue
+		}
+		nameStart := i
+		for i < len(contents) && isTokenChar(contents[i]) {
+			i++
+		}
+		if i == nameStart {
+			return attributes
+		}
+		name := contents[nameStart:i]
+		if i >= len(contents) || contents[i] != '=' {
+			attributes[name] = "true"
+			continue
+		}
+		i++
+		if i >= len(contents) {
+			return attributes
+		}
+		switch contents[i] {
+		case '"', '\'':
+			end := strings.IndexByte(contents[i+1:], contents[i])
+			if end < 0 {
+				return attributes
+			}
+			attributes[name] = contents[i+1 : i+1+end]
+			i += end + 2
+		case '{':
+			var expression string
+			expression, i = jsxExpression(contents, i)
+			if text, ok := stringLiteral(expression); ok {
+				attributes[name] = text
+			}
+		default:
+			return attributes
+		}
+	}
+	return attributes
+}
+
+// jsxExpression reads the braced expression at contents[i], returning its inside and the
+// offset after the closing brace. Braces in string literals don't count.
+func jsxExpression(contents string, i int) (string, int) {
+	start := i + 1
+	depth := 0
+	var quote byte
+	for ; i < len(contents); i++ {
+		c := contents[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return contents[start:i], i + 1
+			}
+		}
+	}
+	return contents[start:], len(contents)
+}
+
+// stringLiteral returns the value of a JavaScript string literal, ok false for anything else.
+func stringLiteral(expression string) (string, bool) {
+	expression = strings.TrimSpace(expression)
+	if len(expression) < 2 || expression[0] != expression[len(expression)-1] {
+		return "", false
+	}
+	switch expression[0] {
+	case '`':
+		inside := expression[1 : len(expression)-1]
+		if strings.Contains(inside, "${") {
+			return "", false
+		}
+		return inside, true
+	case '\'':
+		// strconv only knows double quotes
+		inside := strings.ReplaceAll(expression[1:len(expression)-1], `\'`, `'`)
+		expression = `"` + strings.ReplaceAll(inside, `"`, `\"`) + `"`
+		fallthrough
+	case '"':
+		text, err := strconv.Unquote(expression)
+		return text, err == nil
+	}
+	return "", false
+}
+
+// callArguments splits the arguments of the call whose opening parenthesis is at i, at the
+// commas outside brackets and strings.
+func callArguments(contents string, i int) []string {
+	args := []string{}
+	start := i + 1
+	depth := 0
+	for i++; i < len(contents); i++ {
+		switch contents[i] {
+		case '"', '\'', '`':
+			i = skipString(contents, i) - 1
+		case '(', '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case ')':
+			if depth == 0 {
+				if arg := strings.TrimSpace(contents[start:i]); arg != "" || len(args) > 0 {
+					args = append(args, arg)
+				}
+				return args
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(contents[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return args
+}
+
+// objectString returns the value of a string property of an object literal, like the
+// defaultMessage of {id: 'a', defaultMessage: 'Text'}.
+func objectString(object string, name string) (string, bool) {
+	object = strings.TrimSpace(object)
+	if !strings.HasPrefix(object, "{") || !strings.HasSuffix(object, "}") {
+		return "", false
+	}
+	for _, property := range callArguments("("+object[1:len(object)-1]+")", 0) {
+		colon := strings.IndexByte(property, ':')
+		if colon < 0 {
+			continue
+		}
+		key := strings.TrimSpace(property[:colon])
+		if unquoted, ok := stringLiteral(key); ok {
+			key = unquoted
+		}
+		if key == name {
+			return stringLiteral(property[colon+1:])
+		}
+	}
+	return "", false
+}
+
+// sortExtracted orders messages by file, then line.
+func sortExtracted(messages []ExtractedMessage) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].Path != messages[j].Path {
+			return naturalLess(messages[i].Path, messages[j].Path)
+		}
+		return messages[i].Line < messages[j].Line
+	})
+}
+
+// extractedCatalog builds a catalog of the source locale from the extracted messages, in the
+// order they were found. An id used again with another default text is a conflict.
+func extractedCatalog(catalogPath string, format string, locale string, messages []ExtractedMessage) (*Catalog, []CatalogProblem) {
+	c := newCatalog(catalogPath, format)
+	c.Locale = locale
+	if format == CATALOG_ARB {
+		c.attributes = []string{"@@locale"}
+	}
+	origins := map[string]ExtractedMessage{}
+	problems := []CatalogProblem{}
+	for _, extracted := range messages {
+		if first, ok := origins[extracted.ID]; ok {
+			if first.Text != extracted.Text {
+				problems = append(problems, CatalogProblem{Path: extracted.Path, Line: extracted.Line, Kind: PROBLEM_CONFLICT, Key: extracted.ID, Message: "is " + strconv.Quote(extracted.Text) + " but " + strconv.Quote(first.Text) + " at " + first.Path + ":" + strconv.Itoa(first.Line)})
+			}
+			continue
+		}
+		origins[extracted.ID] = extracted
+		c.add(Message{Key: extracted.ID, Text: extracted.Text, Line: extracted.Line, Description: extracted.Description, origin: extracted.Path + ":" + strconv.Itoa(extracted.Line)})
+	}
+	return c, problems
+}
+
+// KeyStyle are the conventions message ids follow, checked while extracting.
+type KeyStyle struct {
+	// Pattern is a regular expression the whole id must match, e.g. `^[a-z]+(\.[a-z]+){2}$`.
+	Pattern string `yaml:"pattern"`
+	// Segments is the number of dot separated parts of an id, like 3 for area.component.purpose.
+	Segments int `yaml:"segments"`
+	// MaxLength is the longest id allowed.
+	MaxLength int `yaml:"max_length"`
+	// Charset lists the characters ids may use, as the inside of a regular expression
+	// bracket, e.g. "a-z0-9._".
+	Charset string `yaml:"charset"`
+
+	pattern *regexp.Regexp
+	charset *regexp.Regexp
+}
+
+func (k *KeyStyle) parse() error {
+	var err error
+	if k.Pattern != "" {
+		if k.pattern, err = regexp.Compile(k.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+	}
+	if k.Charset != "" {
+		if k.charset, err = regexp.Compile("[^" + k.Charset + "]"); err != nil {
+			return fmt.Errorf("invalid charset: %v", err)
+		}
+	}
+	return nil
+}
+
+// violations lists how an id breaks the conventions.
+func (k *KeyStyle) violations(id string) []string {
+	problems := []string{}
+	if k.pattern != nil && !k.pattern.MatchString(id) {
+		problems = append(problems, "doesn't match "+k.Pattern)
+	}
+	if segments := len(strings.Split(id, ".")); k.Segments > 0 && segments != k.Segments {
+		problems = append(problems, fmt.Sprintf("has %d segments instead of %d", segments, k.Segments))
+	}
+	if k.MaxLength > 0 && len(id) > k.MaxLength {
+		problems = append(problems, fmt.Sprintf("is %d characters long, more than %d", len(id), k.MaxLength))
+	}
+	if k.charset != nil {
+		if bad := k.charset.FindString(id); bad != "" {
+			problems = append(problems, fmt.Sprintf("uses %q, outside [%s]", bad, k.Charset))
+		}
+	}
+	return problems
+}
+
+// lintKeys checks the ids of the extracted messages, once per id, at its first use.
+func lintKeys(style *KeyStyle, messages []ExtractedMessage) []CatalogProblem {
+	problems := []CatalogProblem{}
+	seen := map[string]bool{}
+	for _, extracted := range messages {
+		if seen[extracted.ID] {
+			continue
+		}
+		seen[extracted.ID] = true
+		for _, violation := range style.violations(extracted.ID) {
+			problems = append(problems, CatalogProblem{Path: extracted.Path, Line: extracted.Line, Kind: PROBLEM_KEY_STYLE, Key: extracted.ID, Message: violation})
+		}
+	}
+	return problems
+}
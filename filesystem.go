@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileSystem is the tree a Scanner walks: the local disk, or a remote location.
+// Paths are the ones of the underlying system, built with its Join.
+type FileSystem interface {
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Open(name string) (File, error)
+	Join(elem ...string) string
+}
+
+// File is an open file on a FileSystem.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Closer
+	Stat() (fs.FileInfo, error)
+}
+
+type localFileSystem struct{}
+
+func (localFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(longPath(name))
+}
+
+func (localFileSystem) Open(name string) (File, error) {
+	return os.Open(longPath(name))
+}
+
+func (localFileSystem) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+// isRemote reports whether the scan location is a URL or git repository rather than a local directory.
+func isRemote(location string) bool {
+	return strings.Contains(location, "://") || isGitLocation(location)
+}
+
+// openLocation resolves a scan location to the file system holding it and the root path on it.
+// The returned function releases any connection made.
+func openLocation(location string) (FileSystem, string, func(), error) {
+	if isGitLocation(location) {
+		return openGitRepository(location)
+	}
+	if strings.HasPrefix(location, "ssh://") || strings.HasPrefix(location, "sftp://") {
+		return openSFTP(location)
+	}
+	if strings.HasPrefix(location, "s3://") || strings.HasPrefix(location, "gs://") {
+		return openObjectStore(location)
+	}
+	return localFileSystem{}, normalizeLocalPath(location), func() {}, nil
+}
+
+// validateLocation checks that a local scan location is a readable directory before scanning it.
+// Remote locations are only checked once the scan connects.
+func validateLocation(location string) error {
+	if isRemote(location) {
+		return nil
+	}
+	dirPath := normalizeLocalPath(location)
+	info, err := os.Stat(longPath(dirPath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s does not exist", dirPath)
+		}
+		return fmt.Errorf("error reading %s: %v", dirPath, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dirPath)
+	}
+	dir, err := os.Open(longPath(dirPath))
+	if err != nil {
+		return fmt.Errorf("%s is not readable: %v", dirPath, err)
+	}
+	defer dir.Close()
+	if _, err := dir.Readdirnames(1); err != nil && err != io.EOF {
+		return fmt.Errorf("%s is not readable: %v", dirPath, err)
+	}
+	return nil
+}
+
+// canonicalPath identifies a file independently of the path it was reached by: local files
+// get their absolute path with symlinks resolved, including the archive holding an entry.
+func canonicalPath(fsys FileSystem, filePath string) string {
+	if _, local := fsys.(localFileSystem); !local {
+		return filePath
+	}
+	entry := ""
+	if i := strings.Index(filePath, ARCHIVE_SEPARATOR); i >= 0 {
+		filePath, entry = filePath[:i], filePath[i:]
+	}
+	if abs, err := filepath.Abs(filePath); err == nil {
+		filePath = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(filePath); err == nil {
+		filePath = resolved
+	}
+	return filePath + entry
+}
+
+// normalizeLocalPath cleans up a typed or pasted directory path: surrounding quotes
+// (as added by "Copy as path" on Windows) are dropped, ~ is the home directory as tab
+// completion leaves it, and a bare drive letter means its root.
+func normalizeLocalPath(location string) string {
+	location = strings.TrimSpace(location)
+	if len(location) >= 2 && (location[0] == '"' || location[0] == '\'') && location[len(location)-1] == location[0] {
+		location = location[1 : len(location)-1]
+	}
+	if location == "" {
+		return location
+	}
+	return filepath.Clean(driveRoot(expandHome(location)))
+}
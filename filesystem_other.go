@@ -0,0 +1,18 @@
+//go:build !windows
+
+package main
+
+import "io/fs"
+
+func longPath(name string) string {
+	return name
+}
+
+func driveRoot(location string) string {
+	return location
+}
+
+// followsJunction is only needed for Windows junction points; symlinks are not followed elsewhere.
+func (s *Scanner) followsJunction(dir string, entry fs.DirEntry) bool {
+	return false
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MAX_PATH_LENGTH leaves room below Windows' 260 character MAX_PATH for a file name.
+const MAX_PATH_LENGTH = 248
+
+// longPath turns long absolute paths into extended-length \\?\ paths so they can
+// be opened regardless of the MAX_PATH limit.
+func longPath(name string) string {
+	if len(name) < MAX_PATH_LENGTH || strings.HasPrefix(name, `\\?\`) {
+		return name
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return name
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		// \\server\share\... becomes \\?\UNC\server\share\...
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
+
+// driveRoot makes a bare drive letter such as "C:" mean the drive's root rather
+// than the current directory on that drive.
+func driveRoot(location string) string {
+	if len(location) == 2 && location[1] == ':' {
+		return location + `\`
+	}
+	return location
+}
+
+// followsJunction reports whether the entry is a junction point (or directory symlink)
+// that should be walked like a directory. Targets already walked are skipped to avoid cycles.
+func (s *Scanner) followsJunction(dir string, entry fs.DirEntry) bool {
+	if _, local := s.fs.(localFileSystem); !local || entry.Type()&(fs.ModeSymlink|fs.ModeIrregular) == 0 {
+		return false
+	}
+	linkPath := filepath.Join(dir, entry.Name())
+	info, err := os.Stat(longPath(linkPath))
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	target, err := filepath.EvalSymlinks(linkPath)
+	if err != nil {
+		return false
+	}
+	target = strings.ToLower(target)
+	if s.visitedLinks == nil {
+		s.visitedLinks = map[string]bool{}
+	}
+	if s.visitedLinks[target] {
+		s.log.Log().Msg("❌ Skipping already visited junction: " + linkPath)
+		return false
+	}
+	s.visitedLinks[target] = true
+	return true
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const FILL_EMPTY = "empty"
+const FILL_PSEUDO = "pseudo"
+
+// PSEUDO_LETTERS are the accented look-alikes pseudo-localization swaps letters for.
+var PSEUDO_LETTERS = map[rune]rune{
+	'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ɱ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+func validFill(fill string) error {
+	switch fill {
+	case FILL_EMPTY, FILL_PSEUDO:
+		return nil
+	}
+	return fmt.Errorf("unknown fill %q, expected %s or %s", fill, FILL_EMPTY, FILL_PSEUDO)
+}
+
+// pseudoLocalize accents the letters of a message and brackets it, so untranslated text
+// stands out in the app. Placeholders in braces and HTML tags are left alone.
+func pseudoLocalize(text string) string {
+	if text == "" {
+		return ""
+	}
+	var out strings.Builder
+	out.WriteString("[")
+	braces, tag := 0, false
+	for _, r := range text {
+		switch {
+		case r == '{':
+			braces++
+		case r == '}' && braces > 0:
+			braces--
+		case r == '<' && braces == 0:
+			tag = true
+		case r == '>' && tag:
+			tag = false
+		case braces == 0 && !tag:
+			if pseudo, ok := PSEUDO_LETTERS[r]; ok {
+				r = pseudo
+			}
+		}
+		out.WriteRune(r)
+	}
+	out.WriteString("]")
+	return out.String()
+}
+
+// fixCatalog adds the keys of source missing from c, each after the key preceding it in
+// the source so the file follows the same order, and returns how many were added.
+func fixCatalog(c *Catalog, source *Catalog, fill string) int {
+	if len(c.Keys) == 0 {
+		c.nested = source.nested
+	}
+	added := 0
+	previous := -1
+	for _, key := range source.Keys {
+		if _, ok := c.Messages[key]; ok {
+			previous = indexOf(c.Keys, key)
+			continue
+		}
+		message := source.Messages[key]
+		text := ""
+		if fill == FILL_PSEUDO {
+			text = pseudoLocalize(message.Text)
+		}
+		c.Messages[key] = Message{Key: key, Text: text, Description: message.Description}
+		previous++
+		c.Keys = append(c.Keys[:previous], append([]string{key}, c.Keys[previous:]...)...)
+		added++
+	}
+	return added
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
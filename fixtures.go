@@ -0,0 +1,39 @@
+package main
+
+import "strings"
+
+// PACKAGE_JSON marks the root of a JS project, where the fixtures preset is on by default.
+const PACKAGE_JSON = "package.json"
+
+// FIXTURE_FOLDERS hold test data, Jest snapshots and mocks rather than the application's text.
+var FIXTURE_FOLDERS = map[string]bool{
+	"__fixtures__":  true,
+	"__snapshots__": true,
+	"__mocks__":     true,
+}
+
+// FIXTURE_FILE_MARKERS are in the names of Storybook stories and mock modules, like
+// Button.stories.js or api.mock.js.
+var FIXTURE_FILE_MARKERS = []string{".stories.", ".story.", ".mock.", ".mocks."}
+
+// isFixture tells whether a directory entry is left out by the fixtures preset.
+func isFixture(name string, dir bool) bool {
+	if dir {
+		return FIXTURE_FOLDERS[name]
+	}
+	for _, marker := range FIXTURE_FILE_MARKERS {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludesFixtures tells whether the walk leaves out the fixtures: as configured, or by
+// default when the root is a JS project.
+func (s *Scanner) excludesFixtures() bool {
+	if s.options.ExcludeFixtures != nil {
+		return *s.options.ExcludeFixtures
+	}
+	return statFile(s.fs, s.fs.Join(s.root, PACKAGE_JSON)) != nil
+}
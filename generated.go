@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+)
+
+// GENERATED_HEADER_LINES is how many leading lines are checked for a generated-code header.
+const GENERATED_HEADER_LINES = 5
+
+var GENERATED_MARKERS = [][]byte{
+	[]byte("do not edit"),
+	[]byte("@generated"),
+	[]byte("auto-generated"),
+	[]byte("autogenerated"),
+	[]byte("code generated"),
+}
+
+// isGenerated reports whether one of the first lines of the file carries a
+// "DO NOT EDIT"/"@generated" style header, since findings in generated code are noise.
+func isGenerated(content []byte) bool {
+	rest := content
+	for i := 0; i < GENERATED_HEADER_LINES && len(rest) > 0; i++ {
+		line := rest
+		if end := bytes.IndexByte(rest, '\n'); end >= 0 {
+			line, rest = rest[:end], rest[end+1:]
+		} else {
+			rest = nil
+		}
+		line = bytes.ToLower(line)
+		for _, marker := range GENERATED_MARKERS {
+			if bytes.Contains(line, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DEFAULT_GITHUB_API = "https://api.github.com"
+const DEFAULT_ISSUE_LABEL = "localization"
+const ISSUE_TITLE_PREFIX = "Translation content in "
+const TRACKER_TIMEOUT = 30 * time.Second
+
+// GitHubSettings is where -github-issues opens its issues. The token can be left out of
+// the config and given in the GITHUB_TOKEN environment variable instead.
+type GitHubSettings struct {
+	Token      string `yaml:"token"`
+	Repository string `yaml:"repository"` // owner/name
+	APIURL     string `yaml:"api_url"`
+	Label      string `yaml:"label"`
+	// Ref is the commit or branch source links point to, GITHUB_SHA or the default branch when empty.
+	Ref string `yaml:"ref"`
+}
+
+type gitHubIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+// issueFiles groups the findings by file, in the report's order, for the issue trackers.
+func (r Report) issueFiles() ([]string, map[string][]Match) {
+	paths := []string{}
+	byPath := map[string][]Match{}
+	for _, finding := range r.Findings {
+		p := workspacePath(finding.Path)
+		if _, ok := byPath[p]; !ok {
+			paths = append(paths, p)
+		}
+		byPath[p] = append(byPath[p], finding)
+	}
+	return paths, byPath
+}
+
+// fileGitHubIssues opens an issue for every file with findings that has none yet and
+// updates the existing ones whose findings changed.
+func fileGitHubIssues(settings GitHubSettings, report Report) error {
+	if settings.Repository == "" {
+		return fmt.Errorf("error filing GitHub issues: github repository must be set in the config")
+	}
+	if settings.Token == "" {
+		settings.Token = os.Getenv("GITHUB_TOKEN")
+	}
+	if settings.APIURL == "" {
+		settings.APIURL = DEFAULT_GITHUB_API
+	}
+	if settings.Label == "" {
+		settings.Label = DEFAULT_ISSUE_LABEL
+	}
+	if settings.Ref == "" {
+		settings.Ref = os.Getenv("GITHUB_SHA")
+	}
+	if settings.Ref == "" {
+		settings.Ref = "HEAD"
+	}
+	client := gitHubClient{settings: settings, http: &http.Client{Timeout: TRACKER_TIMEOUT}}
+
+	existing, err := client.openIssues()
+	if err != nil {
+		return err
+	}
+	paths, byPath := report.issueFiles()
+	created, updated := 0, 0
+	for _, p := range paths {
+		title := ISSUE_TITLE_PREFIX + p
+		body := client.issueBody(p, byPath[p])
+		issue, ok := existing[title]
+		if !ok {
+			if err := client.request(http.MethodPost, "/issues", map[string]interface{}{
+				"title": title, "body": body, "labels": []string{settings.Label},
+			}, nil); err != nil {
+				return err
+			}
+			created++
+			continue
+		}
+		if strings.TrimSpace(issue.Body) != strings.TrimSpace(body) {
+			if err := client.request(http.MethodPatch, "/issues/"+strconv.Itoa(issue.Number), map[string]string{"body": body}, nil); err != nil {
+				return err
+			}
+			updated++
+		}
+	}
+	logger.Info().Msg(fmt.Sprintf("🐙 Opened %d and updated %d GitHub issues in %s", created, updated, settings.Repository))
+	return nil
+}
+
+type gitHubClient struct {
+	settings GitHubSettings
+	http     *http.Client
+}
+
+// openIssues returns the open issues carrying the label, by title.
+func (c gitHubClient) openIssues() (map[string]gitHubIssue, error) {
+	issues := map[string]gitHubIssue{}
+	for page := 1; ; page++ {
+		var batch []gitHubIssue
+		query := "/issues?state=open&per_page=100&labels=" + url.QueryEscape(c.settings.Label) + "&page=" + strconv.Itoa(page)
+		if err := c.request(http.MethodGet, query, nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, issue := range batch {
+			if strings.HasPrefix(issue.Title, ISSUE_TITLE_PREFIX) {
+				issues[issue.Title] = issue
+			}
+		}
+		if len(batch) < 100 {
+			return issues, nil
+		}
+	}
+}
+
+func (c gitHubClient) issueBody(filePath string, findings []Match) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "dirwalker found translation content in `%s`:\n\n", filePath)
+	for _, finding := range findings {
+		link := fmt.Sprintf("%s/%s/blob/%s/%s#L%d", c.serverURL(), c.settings.Repository, c.settings.Ref, filePath, finding.Line)
+		fmt.Fprintf(&body, "- [line %d](%s): %s\n", finding.Line, link, finding.Rule)
+	}
+	return body.String()
+}
+
+// serverURL is the web address source links use, which differs from github.com on GitHub Enterprise.
+func (c gitHubClient) serverURL() string {
+	if server := os.Getenv("GITHUB_SERVER_URL"); server != "" {
+		return strings.TrimSuffix(server, "/")
+	}
+	return "https://github.com"
+}
+
+// request calls the repository's REST API at path, decoding the response into reply when given.
+func (c gitHubClient) request(method string, path string, payload interface{}, reply interface{}) error {
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return fmt.Errorf("error encoding GitHub request: %v", err)
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.settings.APIURL, "/")+"/repos/"+c.settings.Repository+path, &body)
+	if err != nil {
+		return fmt.Errorf("error calling GitHub: %v", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	if c.settings.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.settings.Token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling GitHub: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error calling GitHub: %s %s: %s", method, path, resp.Status)
+	}
+	if reply != nil {
+		if err := json.NewDecoder(resp.Body).Decode(reply); err != nil {
+			return fmt.Errorf("error decoding GitHub response: %v", err)
+		}
+	}
+	return nil
+}
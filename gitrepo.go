@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// gitFileSystem is the worktree of a repository cloned in memory.
+type gitFileSystem struct {
+	worktree billy.Filesystem
+}
+
+func (g gitFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	infos, err := g.worktree.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	return entries, nil
+}
+
+func (gitFileSystem) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (g gitFileSystem) Open(name string) (File, error) {
+	f, err := g.worktree.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return gitFile{File: f, worktree: g.worktree}, nil
+}
+
+type gitFile struct {
+	billy.File
+	worktree billy.Filesystem
+}
+
+func (f gitFile) Stat() (fs.FileInfo, error) {
+	return f.worktree.Stat(f.Name())
+}
+
+// isGitLocation reports whether the location names a git repository rather than a directory:
+// an http(s)/git URL, a git+ssh:// URL, or any URL or scp-like address (git@host:repo) ending in .git.
+func isGitLocation(location string) bool {
+	repository, _ := splitGitRef(location)
+	return strings.HasPrefix(repository, "https://") || strings.HasPrefix(repository, "http://") ||
+		strings.HasPrefix(repository, "git://") || strings.HasPrefix(repository, "git+ssh://") ||
+		strings.HasSuffix(repository, ".git")
+}
+
+// splitGitRef separates the optional "#ref" suffix naming the branch or tag to scan.
+func splitGitRef(location string) (string, string) {
+	if i := strings.LastIndex(location, "#"); i >= 0 {
+		return location[:i], location[i+1:]
+	}
+	return location, ""
+}
+
+// openGitRepository shallow-clones the repository in memory and scans its worktree.
+// The ref after "#" is tried as a branch, then as a tag; without one the default branch is used.
+func openGitRepository(location string) (FileSystem, string, func(), error) {
+	repository, ref := splitGitRef(location)
+	repository = strings.TrimPrefix(repository, "git+")
+
+	refs := []plumbing.ReferenceName{""}
+	if ref != "" {
+		refs = []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)}
+	}
+	var err error
+	for _, name := range refs {
+		worktree := memfs.New()
+		logger.Info().Msg("🐙 Cloning " + repository + " " + ref)
+		_, err = git.Clone(memory.NewStorage(), worktree, &git.CloneOptions{
+			URL:           repository,
+			ReferenceName: name,
+			SingleBranch:  true,
+			Depth:         1,
+			Tags:          git.NoTags,
+		})
+		if err == nil {
+			return gitFileSystem{worktree: worktree}, "/", func() {}, nil
+		}
+	}
+	if ref != "" {
+		return nil, "", nil, fmt.Errorf("error cloning %s at %s: %v", repository, ref, err)
+	}
+	return nil, "", nil, fmt.Errorf("error cloning %s: %v", repository, err)
+}
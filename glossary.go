@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+const PROBLEM_TERMINOLOGY = "terminology"
+
+// GlossaryTerm is a term of the source locale and how it has to be translated. Keep is for
+// the names staying as they are in every locale, like the product's.
+type GlossaryTerm struct {
+	Term         string              `yaml:"term"`
+	Translations map[string]string   `yaml:"translations"`
+	Forbidden    map[string][]string `yaml:"forbidden"`
+	Keep         bool                `yaml:"keep"`
+}
+
+// loadGlossary reads a glossary file, a YAML list of terms:
+//
+//   - term: cart
+//     translations: {fr: panier, de: Warenkorb}
+//     forbidden: {fr: [chariot, caddie]}
+func loadGlossary(glossaryPath string) ([]GlossaryTerm, error) {
+	contents, err := os.ReadFile(glossaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading glossary %s: %v", glossaryPath, err)
+	}
+	glossary := []GlossaryTerm{}
+	if err := yaml.Unmarshal(contents, &glossary); err != nil {
+		return nil, fmt.Errorf("error parsing glossary %s: %v", glossaryPath, err)
+	}
+	for i, term := range glossary {
+		if strings.TrimSpace(term.Term) == "" {
+			return nil, fmt.Errorf("error in glossary %s: term %d has no term", glossaryPath, i+1)
+		}
+	}
+	return glossary, nil
+}
+
+// containsWord tells whether text holds word, or words, as a whole, ignoring case.
+func containsWord(text string, word string) bool {
+	text, word = strings.ToLower(text), strings.ToLower(word)
+	if word == "" {
+		return false
+	}
+	isWordRune := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// checkGlossary reports the translations of source messages with a glossary term that
+// use a forbidden word for it, or not its approved translation, and how many per locale.
+// Terms are whole words, case ignored.
+func checkGlossary(catalogs []*Catalog, source *Catalog, glossary []GlossaryTerm) ([]CatalogProblem, map[string]int) {
+	problems := []CatalogProblem{}
+	perLocale := map[string]int{}
+	report := func(c *Catalog, message Message, text string) {
+		problems = append(problems, CatalogProblem{Path: c.Path, Line: message.Line, Kind: PROBLEM_TERMINOLOGY, Key: message.Key, Message: text})
+		perLocale[c.Locale]++
+	}
+	for _, c := range catalogs {
+		if c == source {
+			continue
+		}
+		for _, key := range source.Keys {
+			message, ok := c.Messages[key]
+			if !ok || message.Text == "" {
+				continue
+			}
+			sourceText := source.Messages[key].Text
+			for _, term := range glossary {
+				if !containsWord(sourceText, term.Term) {
+					continue
+				}
+				for _, word := range term.Forbidden[c.Locale] {
+					if containsWord(message.Text, word) {
+						report(c, message, fmt.Sprintf("uses %q for %q", word, term.Term))
+					}
+				}
+				if term.Keep && !containsWord(message.Text, term.Term) {
+					report(c, message, fmt.Sprintf("doesn't keep %q as it is", term.Term))
+				} else if approved := term.Translations[c.Locale]; !term.Keep && approved != "" && !containsWord(message.Text, approved) {
+					report(c, message, fmt.Sprintf("doesn't translate %q as %q", term.Term, approved))
+				}
+			}
+		}
+	}
+	return problems, perLocale
+}
+
+// localeCounts lists counts per locale, "de 1, fr 2".
+func localeCounts(counts map[string]int) string {
+	locales := make([]string, 0, len(counts))
+	for locale := range counts {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for i, locale := range locales {
+		locales[i] = locale + " " + strconv.Itoa(counts[locale])
+	}
+	return strings.Join(locales, ", ")
+}
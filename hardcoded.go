@@ -0,0 +1,249 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// HEURISTIC_HARDCODED_TEXT flags the JSX and HTML text that isn't translated: text nodes
+// with words in them outside any <Message>, <Trans>, data-mc-translate or translate element.
+const HEURISTIC_HARDCODED_TEXT = "hardcoded-text"
+
+// VOID_ELEMENTS never have content, so they're not closed in HTML.
+var VOID_ELEMENTS = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// TRANSLATING_ELEMENTS translate their content.
+var TRANSLATING_ELEMENTS = map[string]bool{"Message": true, "FormattedMessage": true, "Trans": true}
+
+// RAW_TEXT_ELEMENTS hold code rather than text, or the messages of a vue component.
+var RAW_TEXT_ELEMENTS = map[string]bool{"script": true, "style": true, "code": true, "pre": true, "i18n": true}
+
+func validHeuristic(name string) bool {
+	return name == HEURISTIC_HARDCODED_TEXT
+}
+
+// heuristicLines runs a heuristic over a whole file, returning the number of hits per line.
+func heuristicLines(name string, contents string) map[int]int {
+	switch name {
+	case HEURISTIC_HARDCODED_TEXT:
+		return hardcodedText(contents)
+	}
+	return map[int]int{}
+}
+
+// markupScanner walks JavaScript with JSX in it, or an HTML template, for text nodes.
+type markupScanner struct {
+	contents string
+	hits     map[int]int
+}
+
+// hardcodedText finds the untranslated text nodes. A file starting with a tag is read as an
+// HTML template, any other as JavaScript where JSX elements start after (, =, return...
+func hardcodedText(contents string) map[int]int {
+	s := &markupScanner{contents: contents, hits: map[int]int{}}
+	if strings.HasPrefix(strings.TrimSpace(contents), "<") {
+		s.children(0, false)
+	} else {
+		s.code(0, false, false)
+	}
+	return s.hits
+}
+
+// code skips JavaScript up to the closing brace of an expression, when inExpression, or the
+// end, parsing the JSX elements it holds.
+func (s *markupScanner) code(i int, inExpression bool, translated bool) int {
+	depth := 0
+	for i < len(s.contents) {
+		c := s.contents[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(s.contents, i)
+			continue
+		case strings.HasPrefix(s.contents[i:], "//"):
+			if end := strings.IndexByte(s.contents[i:], '\n'); end >= 0 {
+				i += end
+				continue
+			}
+			return len(s.contents)
+		case strings.HasPrefix(s.contents[i:], "/*"):
+			if end := strings.Index(s.contents[i+2:], "*/"); end >= 0 {
+				i += end + 4
+				continue
+			}
+			return len(s.contents)
+		case c == '{':
+			depth++
+		case c == '}':
+			if depth == 0 && inExpression {
+				return i + 1
+			}
+			depth--
+		case c == '<' && startsJSX(s.contents, i):
+			i = s.element(i, translated)
+			continue
+		}
+		i++
+	}
+	return i
+}
+
+// startsJSX tells whether the < at i opens an element rather than compares: it's followed
+// by a name or > (a fragment) and comes where an expression starts.
+func startsJSX(contents string, i int) bool {
+	if i+1 >= len(contents) || !(unicode.IsLetter(rune(contents[i+1])) || contents[i+1] == '>') {
+		return false
+	}
+	before := strings.TrimRight(contents[:i], " \t\r\n")
+	if before == "" || strings.HasSuffix(before, "return") {
+		return true
+	}
+	return strings.IndexByte("(,=?:&|{}[;>", before[len(before)-1]) >= 0
+}
+
+func skipString(contents string, i int) int {
+	quote := contents[i]
+	for i++; i < len(contents); i++ {
+		switch contents[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i + 1
+			}
+		}
+	}
+	// unterminated, a trailing backslash having stepped past the end
+	return len(contents)
+}
+
+// element parses the element whose < is at i, returning the offset after it.
+func (s *markupScanner) element(i int, translated bool) int {
+	if strings.HasPrefix(s.contents[i:], "<!--") {
+		if end := strings.Index(s.contents[i:], "-->"); end >= 0 {
+			return i + end + 3
+		}
+		return len(s.contents)
+	}
+	nameStart := i + 1
+	nameEnd := nameStart
+	for nameEnd < len(s.contents) && isTokenChar(s.contents[nameEnd]) {
+		nameEnd++
+	}
+	name := s.contents[nameStart:nameEnd]
+	attributes := jsxAttributes(s.contents, nameEnd)
+	end, selfClosing := tagEnd(s.contents, nameEnd)
+	if _, ok := attributes[DATA_MC_TRANSLATE]; ok || TRANSLATING_ELEMENTS[name] {
+		translated = true
+	}
+	// an angular-translate directive, or translate="no" keeping the text as it is, or vue-i18n's v-t
+	if _, ok := attributes["translate"]; ok {
+		translated = true
+	}
+	for name := range attributes {
+		if name == "v-t" || strings.HasPrefix(name, "v-t.") {
+			translated = true
+		}
+	}
+	lowered := strings.ToLower(name)
+	if selfClosing || VOID_ELEMENTS[lowered] || strings.HasPrefix(name, "!") {
+		return end
+	}
+	if RAW_TEXT_ELEMENTS[lowered] {
+		if close := strings.Index(strings.ToLower(s.contents[end:]), "</"+lowered); close >= 0 {
+			end += close
+			closing, _ := tagEnd(s.contents, end)
+			return closing
+		}
+		return len(s.contents)
+	}
+	return s.children(end, translated)
+}
+
+// tagEnd finds the end of the tag whose attributes start at i, skipping quoted values and
+// braced expressions, and whether it closes itself.
+func tagEnd(contents string, i int) (int, bool) {
+	for i < len(contents) {
+		switch contents[i] {
+		case '"', '\'':
+			end := strings.IndexByte(contents[i+1:], contents[i])
+			if end < 0 {
+				return len(contents), false
+			}
+			i += end + 2
+			continue
+		case '{':
+			_, i = jsxExpression(contents, i)
+			continue
+		case '>':
+			return i + 1, i > 0 && contents[i-1] == '/'
+		}
+		i++
+	}
+	return i, false
+}
+
+// children parses the content of an element up to its closing tag, recording its text.
+func (s *markupScanner) children(i int, translated bool) int {
+	textStart := i
+	flush := func(end int) {
+		if !translated {
+			s.text(textStart, end)
+		}
+	}
+	for i < len(s.contents) {
+		switch {
+		case strings.HasPrefix(s.contents[i:], "</"):
+			flush(i)
+			end, _ := tagEnd(s.contents, i)
+			return end
+		case s.contents[i] == '<' && i+1 < len(s.contents) && (unicode.IsLetter(rune(s.contents[i+1])) || s.contents[i+1] == '>' || s.contents[i+1] == '!'):
+			flush(i)
+			i = s.element(i, translated)
+			textStart = i
+		case s.contents[i] == '{':
+			flush(i)
+			i = s.code(i+1, true, translated)
+			textStart = i
+		default:
+			i++
+		}
+	}
+	flush(i)
+	return i
+}
+
+// text records the text node contents[start:end] when it reads like words.
+func (s *markupScanner) text(start int, end int) {
+	text := s.contents[start:end]
+	if !readable(text) {
+		return
+	}
+	offset := start + strings.IndexFunc(text, unicode.IsLetter)
+	s.hits[lineAt(s.contents, offset)]++
+}
+
+// readable tells whether text holds a word: two letters or more in a row, entities like
+// &nbsp; aside.
+func readable(text string) bool {
+	for _, field := range strings.Fields(text) {
+		if strings.HasPrefix(field, "&") && strings.HasSuffix(field, ";") {
+			continue
+		}
+		letters := 0
+		for _, r := range field {
+			if unicode.IsLetter(r) {
+				if letters++; letters >= 2 {
+					return true
+				}
+			} else {
+				letters = 0
+			}
+		}
+	}
+	return false
+}
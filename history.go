@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const HISTORY_FILE_NAME = "history.jsonl"
+
+// HistoryEntry is the summary of one scan of a root, appended to the history store.
+type HistoryEntry struct {
+	Root         string        `json:"root"`
+	ScannedAt    time.Time     `json:"scanned_at"`
+	Duration     time.Duration `json:"duration"`
+	FilesScanned int64         `json:"files_scanned"`
+	Matches      int64         `json:"matches"`
+	FoundFiles   []string      `json:"found_files"`
+	Coverage     []Coverage    `json:"coverage"`
+}
+
+var historyMu sync.Mutex
+
+func historyPath() (string, error) {
+	dir, err := stateDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, HISTORY_FILE_NAME), nil
+}
+
+// appendHistory adds the entry to the history store, one JSON object per line.
+func appendHistory(entry HistoryEntry) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	storePath, err := historyPath()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error saving history: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+		return fmt.Errorf("error saving history: %v", err)
+	}
+	f, err := os.OpenFile(storePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error saving history: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error saving history: %v", err)
+	}
+	return nil
+}
+
+// loadHistory returns the stored scans of root, oldest first, or of every root when root is empty.
+func loadHistory(root string) ([]HistoryEntry, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	storePath, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(storePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []HistoryEntry{}, nil
+		}
+		return nil, fmt.Errorf("error reading history: %v", err)
+	}
+	defer f.Close()
+
+	entries := []HistoryEntry{}
+	lines := bufio.NewScanner(f)
+	lines.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for lines.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(lines.Bytes(), &entry); err != nil {
+			// a line cut short by a crash shouldn't lose the rest of the history
+			logger.Warn().Msg("error parsing history line: " + err.Error())
+			continue
+		}
+		if root == "" || entry.Root == root {
+			entries = append(entries, entry)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history: %v", err)
+	}
+	return entries, nil
+}
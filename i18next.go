@@ -0,0 +1,74 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// I18NEXT_CALL matches the t function of i18next: t('key'), i18n.t('key'), i18next.t('key').
+const I18NEXT_CALL = `(?:\bi18n(?:ext)?\.|(?:^|[^\w$.]))t\(\s*["'` + "`" + `]`
+
+var i18nextCall = regexp.MustCompile(I18NEXT_CALL)
+var i18nextHook = regexp.MustCompile(`\b(?:useTranslation|withTranslation)\(`)
+
+// extractI18next reads the keys given to i18next's t function. A key gets the namespace it's
+// looked up in as a prefix, "common:save": the one written in the key, the ns option of the
+// call or, for a bare t, the first namespace given to useTranslation/withTranslation in the file. The
+// default value is the second argument, a string or the defaultValue option.
+func extractI18next(filePath string, contents string) []ExtractedMessage {
+	messages := []ExtractedMessage{}
+	if !strings.Contains(contents, "t(") {
+		return messages
+	}
+	fileNamespace := ""
+	if hook := i18nextHook.FindStringIndex(contents); hook != nil {
+		if args := callArguments(contents, hook[1]-1); len(args) > 0 {
+			fileNamespace = firstString(args[0])
+		}
+	}
+	for _, match := range i18nextCall.FindAllStringIndex(contents, -1) {
+		open := strings.IndexByte(contents[match[0]:match[1]], '(') + match[0]
+		args := callArguments(contents, open)
+		if len(args) == 0 {
+			continue
+		}
+		key, ok := stringLiteral(args[0])
+		if !ok || key == "" {
+			continue
+		}
+		message := ExtractedMessage{Path: filePath, Line: lineAt(contents, open)}
+		namespace := fileNamespace
+		if strings.HasPrefix(contents[match[0]:], "i18n") {
+			// the global instance, not the t of the hook
+			namespace = ""
+		}
+		if len(args) > 1 {
+			if text, ok := stringLiteral(args[1]); ok {
+				message.Text = text
+			} else {
+				message.Text, _ = objectString(args[1], "defaultValue")
+				if ns, ok := objectString(args[1], "ns"); ok {
+					namespace = ns
+				}
+			}
+		}
+		if namespace != "" && !strings.Contains(key, ":") {
+			key = namespace + ":" + key
+		}
+		message.ID = key
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// firstString is the value of a string literal, or of the first one of an array literal.
+func firstString(expression string) string {
+	expression = strings.TrimSpace(expression)
+	if strings.HasPrefix(expression, "[") && strings.HasSuffix(expression, "]") {
+		if items := callArguments("("+expression[1:len(expression)-1]+")", 0); len(items) > 0 {
+			expression = items[0]
+		}
+	}
+	text, _ := stringLiteral(expression)
+	return text
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const DEFAULT_JIRA_ISSUE_TYPE = "Task"
+const JIRA_PAGE_SIZE = 100
+
+// JiraSettings is where -jira-issues opens its issues. The first label marks the issues
+// dirwalker manages; RuleLabels adds labels to the issues of files matched by a rule.
+// SourceURL links findings to the code, with {path} and {line} replaced, e.g.
+// "https://git.example.com/webapp/blob/main/{path}#L{line}". The token can be given in
+// the JIRA_API_TOKEN environment variable instead; with an email it's a Jira Cloud API
+// token, without one a personal access token.
+type JiraSettings struct {
+	URL        string              `yaml:"url"`
+	Email      string              `yaml:"email"`
+	Token      string              `yaml:"token"`
+	Project    string              `yaml:"project"`
+	IssueType  string              `yaml:"issue_type"`
+	Labels     []string            `yaml:"labels"`
+	RuleLabels map[string][]string `yaml:"rule_labels"`
+	SourceURL  string              `yaml:"source_url"`
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string `json:"summary"`
+		Description string `json:"description"`
+	} `json:"fields"`
+}
+
+type jiraClient struct {
+	settings JiraSettings
+	http     *http.Client
+}
+
+// fileJiraIssues opens an issue for every file with findings that has none yet and
+// updates the existing ones whose findings changed.
+func fileJiraIssues(settings JiraSettings, report Report) error {
+	if settings.URL == "" || settings.Project == "" {
+		return fmt.Errorf("error filing Jira issues: jira url and project must be set in the config")
+	}
+	if settings.Token == "" {
+		settings.Token = os.Getenv("JIRA_API_TOKEN")
+	}
+	if settings.IssueType == "" {
+		settings.IssueType = DEFAULT_JIRA_ISSUE_TYPE
+	}
+	if len(settings.Labels) == 0 {
+		settings.Labels = []string{DEFAULT_ISSUE_LABEL}
+	}
+	client := jiraClient{settings: settings, http: &http.Client{Timeout: TRACKER_TIMEOUT}}
+
+	existing, err := client.openIssues()
+	if err != nil {
+		return err
+	}
+	paths, byPath := report.issueFiles()
+	created, updated := 0, 0
+	for _, p := range paths {
+		summary := ISSUE_TITLE_PREFIX + p
+		description := client.issueDescription(p, byPath[p])
+		issue, ok := existing[summary]
+		if !ok {
+			fields := map[string]interface{}{
+				"project":     map[string]string{"key": settings.Project},
+				"issuetype":   map[string]string{"name": settings.IssueType},
+				"summary":     summary,
+				"description": description,
+				"labels":      client.labels(byPath[p]),
+			}
+			if err := client.request(http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}, nil); err != nil {
+				return err
+			}
+			created++
+			continue
+		}
+		if strings.TrimSpace(issue.Fields.Description) != strings.TrimSpace(description) {
+			fields := map[string]interface{}{"description": description, "labels": client.labels(byPath[p])}
+			if err := client.request(http.MethodPut, "/rest/api/2/issue/"+issue.Key, map[string]interface{}{"fields": fields}, nil); err != nil {
+				return err
+			}
+			updated++
+		}
+	}
+	logger.Info().Msg(fmt.Sprintf("📋 Opened %d and updated %d Jira issues in %s", created, updated, settings.Project))
+	return nil
+}
+
+// openIssues returns the unresolved issues of the project carrying the first label, by summary.
+func (c jiraClient) openIssues() (map[string]jiraIssue, error) {
+	jql := fmt.Sprintf("project = %s AND labels = %s AND resolution = Unresolved", strconv.Quote(c.settings.Project), strconv.Quote(c.settings.Labels[0]))
+	issues := map[string]jiraIssue{}
+	for start := 0; ; start += JIRA_PAGE_SIZE {
+		var page struct {
+			Issues []jiraIssue `json:"issues"`
+			Total  int         `json:"total"`
+		}
+		search := map[string]interface{}{
+			"jql":        jql,
+			"startAt":    start,
+			"maxResults": JIRA_PAGE_SIZE,
+			"fields":     []string{"summary", "description"},
+		}
+		if err := c.request(http.MethodPost, "/rest/api/2/search", search, &page); err != nil {
+			return nil, err
+		}
+		for _, issue := range page.Issues {
+			if strings.HasPrefix(issue.Fields.Summary, ISSUE_TITLE_PREFIX) {
+				issues[issue.Fields.Summary] = issue
+			}
+		}
+		if len(page.Issues) == 0 || start+len(page.Issues) >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+// labels are the configured ones plus those mapped from the rules of the findings.
+func (c jiraClient) labels(findings []Match) []string {
+	labels := append([]string{}, c.settings.Labels...)
+	seen := map[string]bool{}
+	for _, label := range labels {
+		seen[label] = true
+	}
+	for _, finding := range findings {
+		for _, label := range c.settings.RuleLabels[finding.Rule] {
+			if !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+	return labels
+}
+
+// issueDescription lists the findings in Jira wiki markup.
+func (c jiraClient) issueDescription(filePath string, findings []Match) string {
+	var description strings.Builder
+	fmt.Fprintf(&description, "dirwalker found translation content in {{%s}}:\n\n", filePath)
+	for _, finding := range findings {
+		location := "line " + strconv.Itoa(finding.Line)
+		if c.settings.SourceURL != "" {
+			link := strings.NewReplacer("{path}", filePath, "{line}", strconv.Itoa(finding.Line)).Replace(c.settings.SourceURL)
+			location = "[" + location + "|" + link + "]"
+		}
+		fmt.Fprintf(&description, "* %s: %s\n", location, finding.Rule)
+	}
+	return description.String()
+}
+
+// request calls the Jira REST API at path, decoding the response into reply when given.
+func (c jiraClient) request(method string, path string, payload interface{}, reply interface{}) error {
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return fmt.Errorf("error encoding Jira request: %v", err)
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.settings.URL, "/")+path, &body)
+	if err != nil {
+		return fmt.Errorf("error calling Jira: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	if c.settings.Email != "" {
+		req.SetBasicAuth(c.settings.Email, c.settings.Token)
+	} else if c.settings.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.settings.Token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling Jira: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error calling Jira: %s %s: %s", method, path, resp.Status)
+	}
+	if reply != nil {
+		if err := json.NewDecoder(resp.Body).Decode(reply); err != nil {
+			return fmt.Errorf("error decoding Jira response: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// JOURNALD_SOCKET is where systemd-journald reads the entries of its native protocol.
+const JOURNALD_SOCKET = "/run/systemd/journal/socket"
+
+// JOURNALD_PRIORITIES are the syslog priorities of the levels.
+var JOURNALD_PRIORITIES = map[zerolog.Level]int{
+	zerolog.TraceLevel: 7,
+	zerolog.DebugLevel: 7,
+	zerolog.InfoLevel:  6,
+	zerolog.WarnLevel:  4,
+	zerolog.ErrorLevel: 3,
+	zerolog.FatalLevel: 2,
+	zerolog.PanicLevel: 0,
+	zerolog.NoLevel:    6,
+}
+
+// journaldWriter sends every line to the journal as an entry, with its fields as entry
+// fields named in capitals: path is PATH.
+type journaldWriter struct {
+	conn *net.UnixConn
+}
+
+func openJournald() (zerolog.LevelWriter, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: JOURNALD_SOCKET, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to journald: %v", err)
+	}
+	return journaldWriter{conn: conn}, nil
+}
+
+func (w journaldWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w journaldWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(p, &fields); err != nil {
+		fields = map[string]interface{}{zerolog.MessageFieldName: string(bytes.TrimSpace(p))}
+	}
+	var entry bytes.Buffer
+	writeJournalField(&entry, "PRIORITY", fmt.Sprint(JOURNALD_PRIORITIES[level]))
+	writeJournalField(&entry, "SYSLOG_IDENTIFIER", SYSLOG_TAG)
+	for name, value := range fields {
+		switch name {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName:
+			continue
+		case zerolog.MessageFieldName:
+			name = "MESSAGE"
+		}
+		text, ok := value.(string)
+		if !ok {
+			encoded, _ := json.Marshal(value)
+			text = string(encoded)
+		}
+		writeJournalField(&entry, journalFieldName(name), text)
+	}
+	if _, err := w.conn.Write(entry.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// journalFieldName is a field name as the journal takes them: capitals, digits and
+// underscores, not starting with one.
+func journalFieldName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		}
+		return '_'
+	}, name)
+	return strings.TrimLeft(name, "_0123456789")
+}
+
+// writeJournalField writes NAME=value, or the length-prefixed form for values with a
+// newline in them.
+func writeJournalField(entry *bytes.Buffer, name string, value string) {
+	if name == "" {
+		return
+	}
+	if !strings.Contains(value, "\n") {
+		entry.WriteString(name + "=" + value + "\n")
+		return
+	}
+	entry.WriteString(name + "\n")
+	binary.Write(entry, binary.LittleEndian, uint64(len(value)))
+	entry.WriteString(value + "\n")
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+func openJournald() (zerolog.LevelWriter, error) {
+	return nil, fmt.Errorf("journald is only available on linux")
+}
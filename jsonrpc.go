@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// rpcMessage is a JSON-RPC 2.0 request, notification or response.
+type rpcMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+type rpcNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+const RPC_METHOD_NOT_FOUND = -32601
+const RPC_INVALID_PARAMS = -32602
+
+// readRPCMessage reads a message framed by a Content-Length header, as LSP does.
+func readRPCMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without a Content-Length")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeRPCMessage writes a message framed by a Content-Length header.
+func writeRPCMessage(w *bufio.Writer, message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// serveRPC answers the requests read from in with handle until an "exit" notification or
+// the end of the input. Notifications get no response.
+func serveRPC(in io.Reader, out *bufio.Writer, handle func(message rpcMessage) (interface{}, *rpcError)) error {
+	reader := bufio.NewReader(in)
+	for {
+		body, err := readRPCMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var message rpcMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			logger.Error().Msg("error parsing message: " + err.Error())
+			continue
+		}
+		if message.Method == "exit" {
+			return nil
+		}
+		result, rpcErr := handle(message)
+		if message.ID == nil {
+			continue
+		}
+		if err := writeRPCMessage(out, rpcResponse{JSONRPC: "2.0", ID: message.ID, Result: result, Error: rpcErr}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds the key bindings of every screen, shown by the ? help.
+type keyMap struct {
+	Quit key.Binding
+	Help key.Binding
+	Log  key.Binding
+
+	// prompt
+	Scan       key.Binding
+	Complete   key.Binding
+	Browse     key.Binding
+	Bookmarks  key.Binding
+	RecentUp   key.Binding
+	RecentDown key.Binding
+
+	// results
+	Again    key.Binding
+	Previous key.Binding
+	Next     key.Binding
+	ListUp   key.Binding
+	ListDown key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+
+	Mark          key.Binding
+	ToggleFailed  key.Binding
+	ToggleMatches key.Binding
+	Retry         key.Binding
+	RetryAll      key.Binding
+	Treemap       key.Binding
+
+	// bookmark menu
+	MenuUp     key.Binding
+	MenuDown   key.Binding
+	MenuChoose key.Binding
+	MenuCancel key.Binding
+
+	// directory picker, on top of the picker's own bindings
+	PickHere    key.Binding
+	ClosePicker key.Binding
+}
+
+var keys = keyMap{
+	Quit: key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+	Help: key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "toggle help")),
+	Log:  key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("ctrl+l", "toggle log")),
+
+	Scan:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "scan")),
+	Complete:   key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "complete path")),
+	Browse:     key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "browse")),
+	Bookmarks:  key.NewBinding(key.WithKeys("ctrl+b"), key.WithHelp("ctrl+b", "bookmarks")),
+	RecentUp:   key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "older recent directory")),
+	RecentDown: key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "newer recent directory")),
+
+	Again:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "scan again")),
+	Previous: key.NewBinding(key.WithKeys("left"), key.WithHelp("←", "previous scan")),
+	Next:     key.NewBinding(key.WithKeys("right"), key.WithHelp("→", "next scan")),
+	ListUp:   key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	ListDown: key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	PageUp:   key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+	PageDown: key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "page down")),
+
+	Mark:          key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mark reviewed/ignored")),
+	ToggleFailed:  key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "failed files")),
+	ToggleMatches: key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "matched lines")),
+	Retry:         key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "retry selected")),
+	RetryAll:      key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "retry all")),
+	Treemap:       key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "density map")),
+
+	MenuUp:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	MenuDown:   key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	MenuChoose: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "scan")),
+	MenuCancel: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "type a path instead")),
+
+	PickHere:    key.NewBinding(key.WithKeys("."), key.WithHelp(".", "pick this directory")),
+	ClosePicker: key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "type a path instead")),
+}
+
+// screenKeys are the bindings of the screen currently shown, in help.KeyMap form.
+type screenKeys [][]key.Binding
+
+func (k screenKeys) ShortHelp() []key.Binding {
+	short := []key.Binding{}
+	for _, group := range k {
+		short = append(short, group[0])
+	}
+	return short
+}
+
+func (k screenKeys) FullHelp() [][]key.Binding {
+	return k
+}
+
+func (m Model) screenKeys() screenKeys {
+	switch {
+	case m.picking:
+		picker := m.picker.KeyMap
+		return screenKeys{
+			{picker.Select, picker.Up, picker.Down, picker.PageUp, picker.PageDown},
+			{picker.Open, picker.Back, picker.GoToTop, picker.GoToLast},
+			{keys.PickHere, keys.ClosePicker},
+			{keys.Help, keys.Log, keys.Quit},
+		}
+	case m.choosing:
+		return screenKeys{
+			{keys.MenuChoose, keys.MenuUp, keys.MenuDown},
+			{keys.MenuCancel},
+			{keys.Help, keys.Log, keys.Quit},
+		}
+	case m.typing:
+		return screenKeys{
+			{keys.Scan, keys.RecentUp, keys.RecentDown},
+			{keys.Complete, keys.Browse, keys.Bookmarks},
+			{keys.Help, keys.Log, keys.Quit},
+		}
+	case m.loading:
+		return screenKeys{{keys.Log, keys.Quit}}
+	case m.err != nil:
+		return screenKeys{{keys.Again}, {keys.Log, keys.Quit}}
+	}
+	if m.showFailed {
+		return screenKeys{
+			{keys.Retry, keys.RetryAll, keys.ToggleFailed},
+			{keys.ListDown, keys.ListUp, keys.PageDown, keys.PageUp},
+			{keys.Help, keys.Log, keys.Quit},
+		}
+	}
+	if m.showMatches {
+		return screenKeys{
+			{keys.ToggleMatches, keys.Again},
+			{keys.ListDown, keys.ListUp, keys.PageDown, keys.PageUp},
+			{keys.Help, keys.Log, keys.Quit},
+		}
+	}
+	if m.showTreemap {
+		return screenKeys{
+			{keys.Treemap, keys.Again},
+			{keys.Previous, keys.Next},
+			{keys.Help, keys.Log, keys.Quit},
+		}
+	}
+	toggleFailed := keys.ToggleFailed
+	toggleFailed.SetEnabled(len(m.history) > 0 && len(m.history[m.current].Failed) > 0)
+	toggleMatches := keys.ToggleMatches
+	toggleMatches.SetEnabled(len(m.history) > 0 && len(m.history[m.current].Matches) > 0)
+	return screenKeys{
+		{keys.Again, keys.Mark, toggleFailed, toggleMatches, keys.Treemap},
+		{keys.ListDown, keys.ListUp, keys.PageDown, keys.PageUp},
+		{keys.Previous, keys.Next},
+		{keys.Help, keys.Log, keys.Quit},
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+const PROBLEM_TOO_LONG = "too-long"
+
+// LengthLimits are the longest texts messages may have, for the components with little
+// room like buttons. The first limit whose pattern matches a key applies to it, MaxLength
+// to the others; 0 is no limit.
+type LengthLimits struct {
+	MaxLength int           `yaml:"max_length"`
+	Keys      []LengthLimit `yaml:"keys"`
+}
+
+// LengthLimit is the longest text of the messages whose key matches Pattern, a regular
+// expression like `\.button\.`.
+type LengthLimit struct {
+	Pattern   string `yaml:"pattern"`
+	MaxLength int    `yaml:"max_length"`
+
+	pattern *regexp.Regexp
+}
+
+func (l *LengthLimits) parse() error {
+	for i := range l.Keys {
+		limit := &l.Keys[i]
+		if limit.Pattern == "" {
+			return fmt.Errorf("limit %d has no pattern", i+1)
+		}
+		var err error
+		if limit.pattern, err = regexp.Compile(limit.Pattern); err != nil {
+			return fmt.Errorf("limit %d: invalid pattern: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// limit is the longest text allowed for key.
+func (l *LengthLimits) limit(key string) int {
+	for _, limit := range l.Keys {
+		if limit.pattern != nil && limit.pattern.MatchString(key) {
+			return limit.MaxLength
+		}
+	}
+	return l.MaxLength
+}
+
+// checkLengths reports the messages of a catalog longer than their limit, counted in
+// characters of the text as written.
+func checkLengths(l *LengthLimits, c *Catalog) []CatalogProblem {
+	problems := []CatalogProblem{}
+	if l.MaxLength == 0 && len(l.Keys) == 0 {
+		return problems
+	}
+	for _, key := range c.Keys {
+		message := c.Messages[key]
+		if limit, length := l.limit(key), utf8.RuneCountInString(message.Text); limit > 0 && length > limit {
+			problems = append(problems, CatalogProblem{Path: c.Path, Line: message.Line, Kind: PROBLEM_TOO_LONG, Key: key, Message: fmt.Sprintf("is %d characters long, more than %d", length, limit)})
+		}
+	}
+	return problems
+}
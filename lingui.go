@@ -0,0 +1,145 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// LINGUI_MACRO matches the t and msg macros of Lingui, as tagged templates (t`Hello`) or
+// given a message descriptor (t({id, message})).
+const LINGUI_MACRO = `(?:^|[^\w$.])(?:t|msg)(?:` + "`" + `|\(\s*\{)`
+
+var linguiMacro = regexp.MustCompile(LINGUI_MACRO)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// extractLingui reads the messages of Lingui's macros and <Trans> components. A message
+// without an explicit id is its own id, as in Lingui's source string catalogs. Messages
+// come out in Lingui's own syntax: ${name} and {name} become {name}, other expressions
+// positional {0} and nested elements numbered tags, <0>bold</0>.
+func extractLingui(filePath string, contents string) []ExtractedMessage {
+	messages := []ExtractedMessage{}
+	add := func(id string, text string, comment string, offset int) {
+		if id == "" {
+			id = text
+		}
+		if id != "" {
+			messages = append(messages, ExtractedMessage{ID: id, Text: text, Description: comment, Path: filePath, Line: lineAt(contents, offset)})
+		}
+	}
+	for _, match := range linguiMacro.FindAllStringIndex(contents, -1) {
+		last := match[1] - 1
+		if contents[last] == '`' {
+			end := skipString(contents, last)
+			if end <= last+1 || contents[end-1] != '`' {
+				// an unterminated template
+				continue
+			}
+			add("", linguiTemplate(contents[last+1:end-1]), "", last)
+			continue
+		}
+		open := strings.IndexByte(contents[match[0]:match[1]], '(') + match[0]
+		if args := callArguments(contents, open); len(args) > 0 {
+			id, _ := objectString(args[0], "id")
+			text, _ := objectString(args[0], "message")
+			comment, _ := objectString(args[0], "comment")
+			add(id, text, comment, open)
+		}
+	}
+	for _, start := range elementStarts(contents, "Trans") {
+		attributes := jsxAttributes(contents, start)
+		id := attributes["id"]
+		if id == "" {
+			// react-i18next's <Trans i18nKey="...">
+			id = attributes["i18nKey"]
+		}
+		text := attributes["message"]
+		end, selfClosing := tagEnd(contents, start)
+		if !selfClosing && text == "" {
+			if close := strings.Index(contents[end:], "</Trans>"); close >= 0 {
+				text = linguiChildren(contents[end : end+close])
+			}
+		}
+		add(id, text, attributes["comment"], start)
+	}
+	return messages
+}
+
+// linguiTemplate turns the inside of a template literal into a message.
+func linguiTemplate(template string) string {
+	var out strings.Builder
+	position := 0
+	for i := 0; i < len(template); i++ {
+		if template[i] == '$' && i+1 < len(template) && template[i+1] == '{' {
+			expression, end := jsxExpression(template, i+1)
+			out.WriteString(linguiPlaceholder(expression, &position))
+			i = end - 1
+			continue
+		}
+		out.WriteByte(template[i])
+	}
+	return out.String()
+}
+
+func linguiPlaceholder(expression string, position *int) string {
+	expression = strings.TrimSpace(expression)
+	if identifier.MatchString(expression) {
+		return "{" + expression + "}"
+	}
+	placeholder := "{" + strconv.Itoa(*position) + "}"
+	*position++
+	return placeholder
+}
+
+// linguiChildren turns the children of a <Trans> element into a message, collapsing the
+// whitespace of line breaks the way JSX does.
+func linguiChildren(children string) string {
+	var out strings.Builder
+	position, tag := 0, 0
+	tags := []int{}
+	for i := 0; i < len(children); {
+		switch c := children[i]; {
+		case c == '{':
+			expression, end := jsxExpression(children, i)
+			if text, ok := stringLiteral(expression); ok {
+				out.WriteString(text)
+			} else if strings.TrimSpace(expression) != "" && !strings.HasPrefix(strings.TrimSpace(expression), "/*") {
+				out.WriteString(linguiPlaceholder(expression, &position))
+			}
+			i = end
+		case c == '<' && i+1 < len(children) && children[i+1] == '/':
+			end, _ := tagEnd(children, i)
+			if len(tags) > 0 {
+				out.WriteString("</" + strconv.Itoa(tags[len(tags)-1]) + ">")
+				tags = tags[:len(tags)-1]
+			}
+			i = end
+		case c == '<' && i+1 < len(children) && unicode.IsLetter(rune(children[i+1])):
+			end, selfClosing := tagEnd(children, i+1)
+			if selfClosing {
+				out.WriteString("<" + strconv.Itoa(tag) + "/>")
+			} else {
+				out.WriteString("<" + strconv.Itoa(tag) + ">")
+				tags = append(tags, tag)
+			}
+			tag++
+			i = end
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	lines := strings.Split(out.String(), "\n")
+	kept := []string{}
+	for i, line := range lines {
+		if len(lines) > 1 {
+			line = strings.TrimSpace(line)
+		}
+		if line != "" || i == 0 {
+			kept = append(kept, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, " "))
+}
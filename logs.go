@@ -0,0 +1,181 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// LATEST_LOG_NAME points at the log file of the latest run, with per-run log files.
+const LATEST_LOG_NAME = "dirwalker-latest.log"
+
+// RUN_LOG_TIME_FORMAT names the log file of a run after the time it started.
+const RUN_LOG_TIME_FORMAT = "20060102-150405"
+
+// RULESET_ID_LENGTH is how much of the ruleset hash the lines of a scan carry.
+const RULESET_ID_LENGTH = 12
+
+// scanRun is set by the commands that scan, the TUI, scan, daemon and serve: only their
+// per-run log files move LATEST_LOG_NAME, for it to point at the log of the latest scan.
+var scanRun bool
+
+// runID tells the lines of a run from the others interleaved in the same log.
+var runID = newRunID()
+
+func newRunID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(id)
+}
+
+// scanLogger is the logger of a scan of root, its lines carrying the root and the hash of
+// the ruleset the clean cache and checkpoints are keyed by.
+func scanLogger(root string, options ScanOptions) zerolog.Logger {
+	return logger.With().Str("root", root).Str("ruleset", rulesetHash(options)[:RULESET_ID_LENGTH]).Logger()
+}
+
+// LogSettings are where the log goes, from the logs section of the config.
+type LogSettings struct {
+	// PerRun gives every run a log file of its own, dirwalker-20060102-150405-<pid>.log,
+	// rather than appending to dirwalker.log, for the log of a scan to be archived with it.
+	PerRun bool `yaml:"per_run"`
+	// Syslog also sends the log to syslog: "local" for the local daemon, or a server as
+	// udp://host:514 or tcp://host:514.
+	Syslog string `yaml:"syslog"`
+	// Journald also sends the log to the systemd journal.
+	Journald bool `yaml:"journald"`
+	// Compress gzips the rotated logs.
+	Compress bool `yaml:"compress"`
+}
+
+// logOutput holds the lines logged before the config is read, as it decides where they
+// go, and writes them there once it is.
+type logOutput struct {
+	mu      sync.Mutex
+	writers []zerolog.LevelWriter
+	opened  bool
+	pending []pendingLine
+}
+
+type pendingLine struct {
+	level zerolog.Level
+	line  []byte
+}
+
+var logFile = &logOutput{}
+
+func (o *logOutput) Write(p []byte) (int, error) {
+	return o.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (o *logOutput) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.opened {
+		o.pending = append(o.pending, pendingLine{level: level, line: append([]byte{}, p...)})
+		return len(p), nil
+	}
+	var err error
+	for i, w := range o.writers {
+		// only the file's errors are reported, not a log service's gone away
+		if _, writeErr := w.WriteLevel(level, p); writeErr != nil && i == 0 {
+			err = writeErr
+		}
+	}
+	return len(p), err
+}
+
+// fileWriter writes the lines of every level to a file.
+type fileWriter struct {
+	io.Writer
+}
+
+func (w fileWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	return w.Write(p)
+}
+
+// openLogs opens the log files and services of the settings and writes the lines logged so
+// far to them. The log goes to dirwalker.log when a per-run file can't be made, and to the
+// files alone without the services that can't be reached.
+func openLogs(settings LogSettings) error {
+	logFile.mu.Lock()
+	defer logFile.mu.Unlock()
+	if logFile.opened {
+		return nil
+	}
+	currentWorkingDirectory, _ := os.Getwd()
+	dir := filepath.Join(currentWorkingDirectory, LOGDIRECTORY)
+	name, err := logFileName(dir, settings)
+	logFile.writers = []zerolog.LevelWriter{fileWriter{&lumberjack.Logger{
+		Filename:   filepath.Join(dir, name),
+		MaxBackups: MAXBACKUPS, // files
+		MaxSize:    MAXSIZE,    // megabytes
+		MaxAge:     MAXAGE,     // days
+		Compress:   settings.Compress,
+	}}}
+	errs := []string{}
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if settings.Syslog != "" {
+		if w, err := openSyslog(settings.Syslog); err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			logFile.writers = append(logFile.writers, w)
+		}
+	}
+	if settings.Journald {
+		if w, err := openJournald(); err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			logFile.writers = append(logFile.writers, w)
+		}
+	}
+	logFile.opened = true
+	for _, pending := range logFile.pending {
+		for _, w := range logFile.writers {
+			w.WriteLevel(pending.level, pending.line)
+		}
+	}
+	logFile.pending = nil
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// logFileName is the log file of the run. The per-run file of a scan is pointed at by
+// LATEST_LOG_NAME, a symlink, or a file holding its name where symlinks can't be made.
+func logFileName(dir string, settings LogSettings) (string, error) {
+	if !settings.PerRun {
+		return LOG_FILE_NAME, nil
+	}
+	name := fmt.Sprintf("dirwalker-%s-%d.log", time.Now().Format(RUN_LOG_TIME_FORMAT), os.Getpid())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return LOG_FILE_NAME, fmt.Errorf("error creating log directory: %v", err)
+	}
+	if !scanRun {
+		return name, nil
+	}
+	latest := filepath.Join(dir, LATEST_LOG_NAME)
+	os.Remove(latest)
+	if err := os.Symlink(name, latest); err != nil {
+		if err := os.WriteFile(latest, []byte(name+"\n"), 0644); err != nil {
+			return name, fmt.Errorf("error pointing %s at the log file: %v", LATEST_LOG_NAME, err)
+		}
+	}
+	return name, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// COMPRESSED_LOG_EXT is the extension lumberjack gives the rotated logs it compresses.
+const COMPRESSED_LOG_EXT = ".gz"
+
+// runLogs dispatches the logs subcommands.
+func runLogs(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker logs clean [options]")
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "clean":
+		runLogsClean(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, "unknown logs command", args[0])
+		os.Exit(2)
+	}
+}
+
+// runLogsClean removes the old logs of dirwalker_logs beyond the retention of the rotated
+// log, MAXBACKUPS files of at most MAXAGE days. lumberjack only prunes the backups of the
+// file it writes, leaving the per-run files and the backups of the earlier ones to pile up.
+func runLogsClean(args []string) {
+	flags := flag.NewFlagSet("logs clean", flag.ExitOnError)
+	configPath := addConfigFlag(flags)
+	dryRun := flags.Bool("dry-run", false, "list the logs that would be removed without removing them")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker logs clean [-dry-run] [-config file]")
+		os.Exit(2)
+	}
+
+	setupLogger()
+	if err := loadConfig(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	currentWorkingDirectory, _ := os.Getwd()
+	dir := filepath.Join(currentWorkingDirectory, LOGDIRECTORY)
+	expired, err := expiredLogs(dir, time.Now())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	failed := false
+	for _, name := range expired {
+		fmt.Println(filepath.Join(LOGDIRECTORY, name))
+		if *dryRun {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			fmt.Fprintln(os.Stderr, fmt.Errorf("error removing log: %v", err))
+			failed = true
+		}
+	}
+	if *dryRun {
+		fmt.Printf("%d logs to remove.\n", len(expired))
+		return
+	}
+	logger.Info().Msg(fmt.Sprintf("🧹 Removed %d old logs", len(expired)))
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// expiredLogs lists the old logs of dir past the retention, newest first: dirwalker.log and
+// the per-run file LATEST_LOG_NAME points at are in use, and kept.
+func expiredLogs(dir string, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading log directory: %v", err)
+	}
+	latest := latestLogName(dir)
+	type oldLog struct {
+		name     string
+		modified time.Time
+	}
+	logs := []oldLog{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || name == LOG_FILE_NAME || name == LATEST_LOG_NAME || name == latest {
+			continue
+		}
+		if !strings.HasPrefix(name, "dirwalker-") || !strings.HasSuffix(strings.TrimSuffix(name, COMPRESSED_LOG_EXT), ".log") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, oldLog{name: name, modified: info.ModTime()})
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].modified.After(logs[j].modified) })
+	cutoff := now.AddDate(0, 0, -MAXAGE)
+	expired := []string{}
+	for i, log := range logs {
+		if i >= MAXBACKUPS || log.modified.Before(cutoff) {
+			expired = append(expired, log.name)
+		}
+	}
+	return expired, nil
+}
+
+// latestLogName is the per-run file LATEST_LOG_NAME points at, as a symlink or a file
+// holding its name, empty without one.
+func latestLogName(dir string) string {
+	latest := filepath.Join(dir, LATEST_LOG_NAME)
+	if target, err := os.Readlink(latest); err == nil {
+		return filepath.Base(target)
+	}
+	contents, err := os.ReadFile(latest)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(contents))
+}
@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"fmt"
+	"log/syslog"
+	"net/url"
+
+	"github.com/rs/zerolog"
+)
+
+// SYSLOG_LOCAL sends the log to the syslog daemon of the machine.
+const SYSLOG_LOCAL = "local"
+
+const SYSLOG_TAG = "dirwalker"
+
+// openSyslog connects to the local syslog daemon, or to a server given as udp://host:514
+// or tcp://host:514, logging under the user facility with the levels of the lines.
+func openSyslog(address string) (zerolog.LevelWriter, error) {
+	network, host := "", ""
+	if address != SYSLOG_LOCAL {
+		u, err := url.Parse(address)
+		if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+			return nil, fmt.Errorf("invalid syslog address %q, expected %s, udp://host:port or tcp://host:port", address, SYSLOG_LOCAL)
+		}
+		network, host = u.Scheme, u.Host
+	}
+	w, err := syslog.Dial(network, host, syslog.LOG_USER|syslog.LOG_INFO, SYSLOG_TAG)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to syslog: %v", err)
+	}
+	return zerolog.SyslogLevelWriter(w), nil
+}
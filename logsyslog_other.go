@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+func openSyslog(address string) (zerolog.LevelWriter, error) {
+	return nil, fmt.Errorf("syslog isn't available on this system")
+}
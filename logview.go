@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+const LOG_TAIL_LINES = 200
+const LOG_PANE_HEIGHT = 8
+
+// logTail keeps the last log lines in memory for the TUI's log pane.
+type logTail struct {
+	mu    sync.Mutex
+	lines []string
+	limit int
+}
+
+var logLines = &logTail{limit: LOG_TAIL_LINES}
+
+// Write receives zerolog's JSON lines and keeps them as "level message", followed by the
+// match of a match's line.
+func (t *logTail) Write(p []byte) (int, error) {
+	var entry struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+		Match
+	}
+	line := strings.TrimSpace(string(p))
+	if err := json.Unmarshal(p, &entry); err == nil {
+		line = entry.Message
+		if entry.Path != "" && entry.Rule != "" {
+			line += " " + entry.Match.String()
+		}
+		if entry.Level != "" {
+			line = strings.ToUpper(entry.Level) + " " + line
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lines = append(t.lines, line)
+	if len(t.lines) > t.limit {
+		t.lines = t.lines[len(t.lines)-t.limit:]
+	}
+	return len(p), nil
+}
+
+// tail returns the last n lines.
+func (t *logTail) tail(n int) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n > len(t.lines) {
+		n = len(t.lines)
+	}
+	return append([]string{}, t.lines[len(t.lines)-n:]...)
+}
+
+// logPane renders the most recent log lines, cut to the terminal width.
+func (m Model) logPane() string {
+	lines := logLines.tail(LOG_PANE_HEIGHT)
+	pane := styles.Status.Render("Log") + "\n"
+	for _, line := range lines {
+		if runes := []rune(line); m.width > 0 && len(runes) > m.width {
+			line = string(runes[:m.width-1]) + symbol("…", ".")
+		}
+		pane += line + "\n"
+	}
+	return pane
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// LSP_SOURCE names dirwalker as the source of the diagnostics shown by the editor.
+const LSP_SOURCE = "dirwalker"
+
+const LSP_SEVERITY_ERROR = 1
+const LSP_SEVERITY_WARNING = 2
+
+// LSP_SYNC_FULL has the editor send the whole document on every change.
+const LSP_SYNC_FULL = 1
+
+const PROBLEM_UNKNOWN_KEY = "unknown-key"
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspTextDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type lspDocumentParams struct {
+	TextDocument   lspTextDocument `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// languageServer publishes the translation policy violations of the documents open in the
+// editor: untranslated text, and keys missing from the source catalog when there's one.
+type languageServer struct {
+	out            *bufio.Writer
+	rules          []Rule
+	ignoreComments bool
+	catalogPaths   []string
+	sourceLocale   string
+	// keys are the keys of the source catalogs, nil without catalogs
+	keys     map[string]bool
+	docs     map[string]string
+	shutdown bool
+}
+
+// loadKeys reads the keys of the source locale's catalogs.
+func (ls *languageServer) loadKeys() error {
+	if len(ls.catalogPaths) == 0 {
+		return nil
+	}
+	catalogs, err := loadCatalogs(ls.catalogPaths)
+	if err != nil {
+		return err
+	}
+	keys := map[string]bool{}
+	for _, c := range catalogs {
+		if c.Locale == ls.sourceLocale || c.Locale == "" {
+			for _, key := range c.Keys {
+				keys[key] = true
+			}
+		}
+	}
+	ls.keys = keys
+	logger.Info().Msg(fmt.Sprintf("🧩 Loaded %d keys of the %s catalogs", len(keys), ls.sourceLocale))
+	return nil
+}
+
+// knownKey tells whether a key is in the source catalogs, an i18next "ns:key" being
+// looked up as "ns.key" too.
+func (ls *languageServer) knownKey(key string) bool {
+	return ls.keys[key] || ls.keys[strings.Replace(key, ":", ".", 1)]
+}
+
+// uriPath is the file path of a file:// URI. On Windows, file:///C:/src/App.js is C:\src\App.js.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	uriPath := u.Path
+	if len(uriPath) >= 3 && uriPath[0] == '/' && filepath.VolumeName(uriPath[1:]) != "" {
+		uriPath = uriPath[1:]
+	}
+	return filepath.FromSlash(uriPath)
+}
+
+// utf16Length is the length of text in the UTF-16 code units LSP positions count.
+func utf16Length(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// lineRange spans a line from its first non-blank character to its end.
+func lineRange(lines []string, line int) lspRange {
+	text := ""
+	if line >= 1 && line <= len(lines) {
+		text = strings.TrimRight(lines[line-1], "\r")
+	}
+	indent := len(text) - len(strings.TrimLeft(text, " \t"))
+	return lspRange{
+		Start: lspPosition{Line: line - 1, Character: utf16Length(text[:indent])},
+		End:   lspPosition{Line: line - 1, Character: utf16Length(text)},
+	}
+}
+
+// diagnose lists the violations of a document.
+func (ls *languageServer) diagnose(uri string, text string) []lspDiagnostic {
+	diagnostics := []lspDiagnostic{}
+	filePath := uriPath(uri)
+	if !isCandidate(filePath) || isIgnored([]byte(text)) {
+		return diagnostics
+	}
+	lines := strings.Split(text, "\n")
+	countMatches(ls.rules, text, 0, ls.ignoreComments, func(match Match) {
+		diagnostics = append(diagnostics, lspDiagnostic{
+			Range: lineRange(lines, match.Line), Severity: LSP_SEVERITY_WARNING, Code: HEURISTIC_HARDCODED_TEXT,
+			Source: LSP_SOURCE, Message: "Text isn't translated",
+		})
+	})
+	if ls.keys == nil {
+		return diagnostics
+	}
+	// a component's own <i18n> blocks, as last saved, define keys too
+	local := map[string]bool{}
+	if filepath.Ext(filePath) == VUE_EXT && vueI18nBlock.MatchString(text) {
+		if catalogs, err := vueCatalogs(filePath); err == nil {
+			for _, c := range catalogs {
+				for _, key := range c.Keys {
+					local[key] = true
+				}
+			}
+		}
+	}
+	for _, message := range extractMessages(filePath, text) {
+		if local[message.ID] || ls.knownKey(message.ID) {
+			continue
+		}
+		diagnostics = append(diagnostics, lspDiagnostic{
+			Range: lineRange(lines, message.Line), Severity: LSP_SEVERITY_ERROR, Code: PROBLEM_UNKNOWN_KEY,
+			Source: LSP_SOURCE, Message: fmt.Sprintf("Unknown key %q, not in the %s catalog", message.ID, ls.sourceLocale),
+		})
+	}
+	return diagnostics
+}
+
+func (ls *languageServer) publish(uri string, diagnostics []lspDiagnostic) error {
+	return writeRPCMessage(ls.out, rpcNotification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  map[string]interface{}{"uri": uri, "diagnostics": diagnostics},
+	})
+}
+
+// handle answers a request, or acts on a notification, returning the result of a request.
+func (ls *languageServer) handle(message rpcMessage) (interface{}, *rpcError) {
+	var params lspDocumentParams
+	if len(message.Params) > 0 && strings.HasPrefix(message.Method, "textDocument/") {
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			return nil, &rpcError{Code: RPC_INVALID_PARAMS, Message: err.Error()}
+		}
+	}
+	uri := params.TextDocument.URI
+	var err error
+	switch message.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{"openClose": true, "change": LSP_SYNC_FULL, "save": true},
+			},
+			"serverInfo": map[string]string{"name": "dirwalker", "version": VERSION},
+		}, nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	case "shutdown":
+		ls.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		ls.docs[uri] = params.TextDocument.Text
+		err = ls.publish(uri, ls.diagnose(uri, ls.docs[uri]))
+	case "textDocument/didChange":
+		if changes := params.ContentChanges; len(changes) > 0 {
+			ls.docs[uri] = changes[len(changes)-1].Text
+		}
+		err = ls.publish(uri, ls.diagnose(uri, ls.docs[uri]))
+	case "textDocument/didSave":
+		// a saved catalog changes the keys of every document
+		if isCandidate(uriPath(uri)) {
+			return nil, nil
+		}
+		if err = ls.loadKeys(); err != nil {
+			break
+		}
+		for open, text := range ls.docs {
+			if err = ls.publish(open, ls.diagnose(open, text)); err != nil {
+				break
+			}
+		}
+	case "textDocument/didClose":
+		delete(ls.docs, uri)
+		err = ls.publish(uri, []lspDiagnostic{})
+	default:
+		return nil, &rpcError{Code: RPC_METHOD_NOT_FOUND, Message: "unknown method " + message.Method}
+	}
+	if err != nil {
+		logger.Error().Msg(err.Error())
+	}
+	return nil, nil
+}
+
+// serve reads the messages of the editor until it exits, telling whether it shut down first.
+func (ls *languageServer) serve(in io.Reader) (bool, error) {
+	err := serveRPC(in, ls.out, ls.handle)
+	return ls.shutdown, err
+}
+
+// runLSP serves the Language Server Protocol on the standard input and output, for the
+// editors to show the translation policy violations inline.
+func runLSP(args []string) {
+	flags := flag.NewFlagSet("lsp", flag.ExitOnError)
+	var catalogPaths stringList
+	flags.Var(&catalogPaths, "catalog", "catalog file or directory whose source locale keys are the known ones (repeatable)")
+	sourceLocale := flags.String("source", DEFAULT_SOURCE_LOCALE, "locale of the catalog keys are looked up in")
+	options := addScanFlags(flags)
+	configPath := addConfigFlag(flags)
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker lsp [-catalog path]... [-source en] [-config file]")
+		os.Exit(2)
+	}
+
+	setupLogger()
+	if err := applyConfig(*configPath, options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// the suppressions of the config apply to the untranslated text too
+	rules := []Rule{{Name: HEURISTIC_HARDCODED_TEXT, Heuristic: HEURISTIC_HARDCODED_TEXT}}
+	for _, rule := range options.Rules {
+		if rule.Suppress {
+			rules = append(rules, rule)
+		}
+	}
+	ls := &languageServer{
+		out:            bufio.NewWriter(os.Stdout),
+		rules:          rules,
+		ignoreComments: options.IgnoreComments,
+		catalogPaths:   catalogPaths,
+		sourceLocale:   *sourceLocale,
+		docs:           map[string]string{},
+	}
+	if err := ls.loadKeys(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger.Info().Msg("🧩 Language server started")
+	shutdown, err := ls.serve(os.Stdin)
+	if err != nil {
+		logger.Error().Msg(err.Error())
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if !shutdown {
+		os.Exit(1)
+	}
+}
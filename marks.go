@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const MARKS_FILE_NAME = "marks.json"
+
+const MARK_REVIEWED = "reviewed"
+const MARK_IGNORED = "ignored"
+
+// Marks holds the triage state of found files by path, kept between runs.
+type Marks map[string]string
+
+func loadMarks() (Marks, error) {
+	dir, err := stateDirectory()
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, MARKS_FILE_NAME))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Marks{}, nil
+		}
+		return nil, fmt.Errorf("error reading marks: %v", err)
+	}
+	marks := Marks{}
+	if err := json.Unmarshal(contents, &marks); err != nil {
+		return nil, fmt.Errorf("error parsing marks: %v", err)
+	}
+	return marks, nil
+}
+
+func (marks Marks) save() error {
+	dir, err := stateDirectory()
+	if err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error saving marks: %v", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error saving marks: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, MARKS_FILE_NAME), contents, 0644); err != nil {
+		return fmt.Errorf("error saving marks: %v", err)
+	}
+	return nil
+}
+
+// cycle moves the file's mark from none to reviewed to ignored and back to none.
+func (marks Marks) cycle(filePath string) {
+	switch marks[filePath] {
+	case "":
+		marks[filePath] = MARK_REVIEWED
+	case MARK_REVIEWED:
+		marks[filePath] = MARK_IGNORED
+	default:
+		delete(marks, filePath)
+	}
+}
+
+// badge is shown in front of a file in the results list.
+func (marks Marks) badge(filePath string) string {
+	switch marks[filePath] {
+	case MARK_REVIEWED:
+		return symbol("✓ ", "[r] ")
+	case MARK_IGNORED:
+		return symbol("✗ ", "[i] ")
+	}
+	return symbol("  ", "    ")
+}
+
+// counts returns how many of the files are reviewed and ignored.
+func (marks Marks) counts(filePaths []string) (int, int) {
+	reviewed, ignored := 0, 0
+	for _, filePath := range filePaths {
+		switch marks[filePath] {
+		case MARK_REVIEWED:
+			reviewed++
+		case MARK_IGNORED:
+			ignored++
+		}
+	}
+	return reviewed, ignored
+}
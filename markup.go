@@ -0,0 +1,55 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+const PROBLEM_HTML = "html"
+
+// HTML_ELEMENTS are the tags reported in messages. Other tags, like the <0> of Lingui or
+// the <link> and <bold> of rich text formatting, stand for components and are expected.
+var HTML_ELEMENTS = map[string]bool{
+	"a": true, "abbr": true, "b": true, "big": true, "blockquote": true, "br": true, "button": true,
+	"center": true, "cite": true, "code": true, "del": true, "div": true, "em": true, "font": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "i": true,
+	"img": true, "ins": true, "kbd": true, "label": true, "li": true, "mark": true, "ol": true,
+	"p": true, "pre": true, "q": true, "s": true, "small": true, "span": true, "strike": true,
+	"strong": true, "sub": true, "sup": true, "table": true, "td": true, "th": true, "tr": true,
+	"u": true, "ul": true,
+}
+
+var htmlTag = regexp.MustCompile(`</?([A-Za-z][A-Za-z0-9]*)(?:\s[^<>]*)?/?>`)
+var htmlEntity = regexp.MustCompile(`&(?:[A-Za-z][A-Za-z0-9]*|#[0-9]+|#[xX][0-9A-Fa-f]+);`)
+
+// htmlInText lists the HTML tags and entities written in a message, each once.
+func htmlInText(text string) []string {
+	found := []string{}
+	seen := map[string]bool{}
+	for _, match := range htmlTag.FindAllStringSubmatch(text, -1) {
+		if HTML_ELEMENTS[strings.ToLower(match[1])] && !seen[match[0]] {
+			seen[match[0]] = true
+			found = append(found, match[0])
+		}
+	}
+	for _, entity := range htmlEntity.FindAllString(text, -1) {
+		if !seen[entity] {
+			seen[entity] = true
+			found = append(found, entity)
+		}
+	}
+	return found
+}
+
+// checkHTML reports the messages holding raw HTML, better split into several messages or
+// written with the rich text formatting of the i18n library.
+func checkHTML(c *Catalog) []CatalogProblem {
+	problems := []CatalogProblem{}
+	for _, key := range c.Keys {
+		message := c.Messages[key]
+		if found := htmlInText(message.Text); len(found) > 0 {
+			problems = append(problems, CatalogProblem{Path: c.Path, Line: message.Line, Kind: PROBLEM_HTML, Key: key, Message: "has HTML in it: " + strings.Join(found, " ")})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"runtime/debug"
+)
+
+const MIB = 1 << 20
+
+// MEMORY_BASELINE is what a scan needs besides the files it reads: the runtime, the rules
+// and the results, in bytes.
+const MEMORY_BASELINE = 16 * MIB
+
+// MIN_STREAM_WINDOW is the smallest window of a file matched at once, in bytes.
+const MIN_STREAM_WINDOW = 256 * 1024
+
+// MEMORY_PER_WINDOW is how many times its window a worker holds: the bytes read, their
+// string copy and what the rules allocate matching it.
+const MEMORY_PER_WINDOW = 4
+
+// applyMemoryBudget fits the workers and the buffer size to options.MaxMemory, returning
+// the window of a file each worker may match at once, 0 without a budget. The budget is
+// also the soft limit of the Go runtime, collecting garbage sooner as it gets near. Each
+// scan logs the budget, in logMemoryBudget, once its root is known.
+func applyMemoryBudget(options *ScanOptions) int {
+	if options.MaxMemory <= 0 {
+		return 0
+	}
+	budget := options.MaxMemory * MIB
+	debug.SetMemoryLimit(int64(budget))
+	available := budget - MEMORY_BASELINE
+	if available < MIN_STREAM_WINDOW*MEMORY_PER_WINDOW {
+		available = MIN_STREAM_WINDOW * MEMORY_PER_WINDOW
+	}
+	for options.Workers > 1 && available/options.Workers/MEMORY_PER_WINDOW < MIN_STREAM_WINDOW {
+		options.Workers--
+	}
+	window := available / options.Workers / MEMORY_PER_WINDOW
+	if options.BufferSize > window/MEMORY_PER_WINDOW {
+		options.BufferSize = window / MEMORY_PER_WINDOW
+	}
+	return window
+}
+
+func (s *Scanner) logMemoryBudget() {
+	if s.streamWindow > 0 {
+		s.log.Info().Msgf("🧮 Memory budget of %d MiB: %d workers, %d bytes buffers, %d bytes windows", s.options.MaxMemory, s.options.Workers, s.options.BufferSize, s.streamWindow)
+	}
+}
+
+// streamContent matches a file a window of whole lines at a time, rather than all of it,
+// filling in entry. Constructs spanning two windows, like a multi-line element, may be missed.
+func (s *Scanner) streamContent(filePath string, limit int, entry *indexEntry) (int, []Match, error) {
+	f, err := s.fs.Open(filePath)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+	return s.streamReader(filePath, f, limit, entry)
+}
+
+// streamReader matches the content of r, at filePath, a window at a time.
+func (s *Scanner) streamReader(filePath string, r io.Reader, limit int, entry *indexEntry) (int, []Match, error) {
+	reader := bufio.NewReaderSize(r, s.options.BufferSize)
+	matches := 0
+	var findings []Match
+	chunk := make([]byte, 0, s.streamWindow)
+	for line, first := 1, true; ; first = false {
+		chunk = chunk[:0]
+		var readErr error
+		for len(chunk) < s.streamWindow {
+			var piece []byte
+			piece, readErr = reader.ReadSlice('\n')
+			chunk = append(chunk, piece...)
+			if readErr != bufio.ErrBufferFull && readErr != nil {
+				break
+			}
+		}
+		if readErr != nil && readErr != io.EOF && readErr != bufio.ErrBufferFull {
+			return 0, nil, readErr
+		}
+		s.bytesRead.Add(int64(len(chunk)))
+		s.throttle.wait(len(chunk))
+		window := chunk
+		if first {
+			window, entry.bom = stripBOM(window)
+			if entry.generated = isGenerated(window); entry.generated && !s.options.IncludeGenerated {
+				return 0, nil, nil
+			}
+		}
+		if isIgnored(window) {
+			entry.ignored = true
+			return 0, nil, nil
+		}
+		remaining := 0
+		if limit > 0 {
+			remaining = limit - matches
+		}
+		count, windowFindings := s.matchContent(filePath, window, line, remaining)
+		findings = append(findings, windowFindings...)
+		matches += count
+		line += bytes.Count(chunk, []byte("\n"))
+		if readErr == io.EOF || (limit > 0 && matches >= limit) {
+			return matches, findings, nil
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+// DEFAULT_NAMESPACE holds the keys without a namespace, like i18next's default one.
+const DEFAULT_NAMESPACE = "translation"
+
+// NAMESPACE_PLACEHOLDER in the -o path of catalog extract writes one file per namespace.
+const NAMESPACE_PLACEHOLDER = "{ns}"
+
+// LOCALE_PLACEHOLDER in the -o path of catalog extract is replaced by the source locale.
+const LOCALE_PLACEHOLDER = "{locale}"
+
+// namespaceOf splits a key into its namespace and the rest: i18next's "common:save" or the
+// first segment of "common.save". A key with neither is in DEFAULT_NAMESPACE.
+func namespaceOf(key string) (string, string) {
+	if i := strings.IndexByte(key, ':'); i > 0 {
+		return key[:i], key[i+1:]
+	}
+	if i := strings.IndexByte(key, '.'); i > 0 {
+		return key[:i], key[i+1:]
+	}
+	return DEFAULT_NAMESPACE, key
+}
+
+// NamespaceCount is the number of messages of a namespace.
+type NamespaceCount struct {
+	Namespace string
+	Messages  int
+}
+
+// namespaceCounts counts the messages of every namespace of the catalog, by name.
+func namespaceCounts(c *Catalog) []NamespaceCount {
+	counts := map[string]int{}
+	for _, key := range c.Keys {
+		namespace, _ := namespaceOf(key)
+		counts[namespace]++
+	}
+	result := []NamespaceCount{}
+	for namespace, messages := range counts {
+		result = append(result, NamespaceCount{Namespace: namespace, Messages: messages})
+	}
+	sort.Slice(result, func(i, j int) bool { return naturalLess(result[i].Namespace, result[j].Namespace) })
+	return result
+}
+
+// namespaceTable renders the messages per namespace.
+func namespaceTable(c *Catalog) (string, error) {
+	data := pterm.TableData{{"Namespace", "Messages"}}
+	for _, count := range namespaceCounts(c) {
+		data = append(data, []string{count.Namespace, strconv.Itoa(count.Messages)})
+	}
+	data = append(data, []string{"total", strconv.Itoa(len(c.Keys))})
+	table, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return "", fmt.Errorf("error rendering namespaces: %v", err)
+	}
+	return table + "\n", nil
+}
+
+// namespacePath fills the placeholders of an output path.
+func namespacePath(output string, namespace string, locale string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(output, NAMESPACE_PLACEHOLDER, namespace), LOCALE_PLACEHOLDER, locale)
+}
+
+// splitNamespaces makes a catalog per namespace, its keys without the namespace, written to
+// the output path with the namespace filled in: locales/{locale}/{ns}.json.
+func splitNamespaces(c *Catalog, output string) []*Catalog {
+	catalogs := []*Catalog{}
+	byNamespace := map[string]*Catalog{}
+	for _, key := range c.Keys {
+		namespace, rest := namespaceOf(key)
+		part, ok := byNamespace[namespace]
+		if !ok {
+			part = newCatalog(namespacePath(output, namespace, c.Locale), c.Format)
+			part.Locale = c.Locale
+			part.attributes = c.attributes
+			byNamespace[namespace] = part
+			catalogs = append(catalogs, part)
+		}
+		message := c.Messages[key]
+		message.Key = rest
+		part.add(message)
+	}
+	return catalogs
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strconv"
+)
+
+// DEFAULT_NETWORK_BUFFER_SIZE is the read buffer size on network file systems, where every
+// read is a round-trip to the server.
+const DEFAULT_NETWORK_BUFFER_SIZE = 1 << 20
+
+// tuneForNetwork reads in NetworkBufferSize chunks when the root is remote or on a network
+// file system, like an NFS or SMB mount.
+func (s *Scanner) tuneForNetwork() {
+	if s.options.NetworkBufferSize < 1 {
+		return
+	}
+	if _, local := s.fs.(localFileSystem); local && !onNetworkFileSystem(s.root) {
+		return
+	}
+	s.options.BufferSize = s.options.NetworkBufferSize
+	if s.streamWindow > 0 && s.options.BufferSize > s.streamWindow/MEMORY_PER_WINDOW {
+		s.options.BufferSize = s.streamWindow / MEMORY_PER_WINDOW
+	}
+	s.log.Info().Msg("📡 " + s.root + " is on a network file system, reading it in " + strconv.Itoa(s.options.BufferSize) + " bytes buffers")
+}
+
+// fileInfo reads the size and modification time of a file for the clean cache and the
+// index. In stat-light mode they come from the directory listing, which most network file
+// systems send along with the names, rather than from a round-trip of their own.
+func (s *Scanner) fileInfo(file candidateFile) fs.FileInfo {
+	if s.options.StatLight && file.entry != nil {
+		info, err := file.entry.Info()
+		if err != nil {
+			return nil
+		}
+		return info
+	}
+	return statFile(s.fs, file.path)
+}
+
+// canonical is the canonicalPath of a file. Stat-light mode doesn't resolve symlinks, a
+// stat per directory of the path, so a file reached through a junction can be reported twice.
+func (s *Scanner) canonical(filePath string) string {
+	if _, local := s.fs.(localFileSystem); local && s.options.StatLight {
+		if abs, err := filepath.Abs(filePath); err == nil {
+			return abs
+		}
+		return filePath
+	}
+	return canonicalPath(s.fs, filePath)
+}
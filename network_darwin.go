@@ -0,0 +1,21 @@
+package main
+
+import "syscall"
+
+// NETWORK_FILE_SYSTEMS are the names statfs gives the network file systems.
+var NETWORK_FILE_SYSTEMS = map[string]bool{"nfs": true, "smbfs": true, "afpfs": true, "webdav": true}
+
+func onNetworkFileSystem(dirPath string) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dirPath, &stat); err != nil {
+		return false
+	}
+	name := []byte{}
+	for _, c := range stat.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+	return NETWORK_FILE_SYSTEMS[string(name)]
+}
@@ -0,0 +1,22 @@
+package main
+
+import "syscall"
+
+// NETWORK_FILE_SYSTEMS are the statfs magic numbers of the network file systems.
+var NETWORK_FILE_SYSTEMS = map[int64]bool{
+	0x6969:     true, // NFS
+	0x517b:     true, // SMB
+	0xff534d42: true, // CIFS
+	0xfe534d42: true, // SMB2
+	0x5346414f: true, // AFS
+	0x00c36400: true, // Ceph
+	0x01021997: true, // 9P
+}
+
+func onNetworkFileSystem(dirPath string) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dirPath, &stat); err != nil {
+		return false
+	}
+	return NETWORK_FILE_SYSTEMS[int64(stat.Type)]
+}
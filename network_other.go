@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+func onNetworkFileSystem(dirPath string) bool {
+	return false
+}
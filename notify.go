@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const DEFAULT_NOTIFY_AFTER = 10 * time.Second
+
+// Notification tells that the files found under a root changed between two scans.
+type Notification struct {
+	Root     string
+	Entry    HistoryEntry
+	Previous HistoryEntry
+	Added    []string
+	Removed  []string
+}
+
+// CoverageBefore and CoverageAfter are the percentages of candidate files with
+// translation content in the previous and the latest scan.
+func (n Notification) CoverageBefore() float64 {
+	return coveragePercent(n.Previous.Coverage)
+}
+
+func (n Notification) CoverageAfter() float64 {
+	return coveragePercent(n.Entry.Coverage)
+}
+
+func coveragePercent(coverage []Coverage) float64 {
+	candidates, matched := 0, 0
+	for _, c := range coverage {
+		candidates += c.Candidates
+		matched += c.Matched
+	}
+	if candidates == 0 {
+		return 0
+	}
+	return 100 * float64(matched) / float64(candidates)
+}
+
+// Summary is the one line description of the change.
+func (n Notification) Summary() string {
+	return n.Root + ": " + strconv.Itoa(len(n.Entry.FoundFiles)) + " files with translation content (" +
+		strconv.Itoa(len(n.Added)) + " new, " + strconv.Itoa(len(n.Removed)) + " gone)"
+}
+
+// notify reports a change found by a scan and posts it to the configured webhooks.
+func notify(n Notification) {
+	logger.Info().Msg("🔔 " + n.Summary())
+	for _, webhook := range config.Webhooks {
+		if err := webhook.post(n); err != nil {
+			logger.Error().Msg(err.Error())
+		}
+	}
+}
+
+// notifyScan posts a scan of `dirwalker scan -notify`, changed or not, for CI runs. The
+// changes are since the previous scan of its root in the history, none without one.
+func notifyScan(entry HistoryEntry) {
+	n := Notification{Root: entry.Root, Entry: entry}
+	previous, err := loadHistory(entry.Root)
+	if err != nil {
+		logger.Error().Msg(err.Error())
+	}
+	if len(previous) > 0 {
+		n.Previous = previous[len(previous)-1]
+		n.Added, n.Removed = diffFiles(n.Previous.FoundFiles, entry.FoundFiles)
+	}
+	notify(n)
+}
+
+// notifyScanDone sends the summary of a finished TUI scan as a desktop notification, so
+// a long scan can be left running in the background.
+func notifyScanDone(results Results) tea.Cmd {
+	return func() tea.Msg {
+		body := fmt.Sprintf("%d files with translation content in %s (%s)",
+			len(results.FoundFiles), results.Location, results.Duration.Round(time.Millisecond))
+		if err := desktopNotify("Dirwalker scan finished", body); err != nil {
+			logger.Error().Msg(err.Error())
+		}
+		return nil
+	}
+}
+
+// desktopNotify shows a native notification with the tools each platform ships with:
+// osascript on macOS, a PowerShell toast on Windows and notify-send elsewhere.
+func desktopNotify(title string, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := "display notification " + appleScriptString(body) + " with title " + appleScriptString(title)
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:DIRWALKER_TITLE)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode($env:DIRWALKER_BODY)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('dirwalker').Show([Windows.UI.Notifications.ToastNotification]::new($template))`
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+		// passed through the environment to avoid quoting them into the script
+		cmd.Env = append(cmd.Environ(), "DIRWALKER_TITLE="+title, "DIRWALKER_BODY="+body)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=dirwalker", title, body)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error sending desktop notification: %v %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
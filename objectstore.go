@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const S3_ENDPOINT = "s3.amazonaws.com"
+const GCS_ENDPOINT = "storage.googleapis.com"
+
+// objectFileSystem presents a bucket as a tree, using "/" in object keys as the directory separator.
+type objectFileSystem struct {
+	client *minio.Client
+	bucket string
+}
+
+// objectEntry is a listed object or common prefix.
+type objectEntry struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+func (e objectEntry) Name() string               { return e.name }
+func (e objectEntry) IsDir() bool                { return e.dir }
+func (e objectEntry) Size() int64                { return e.size }
+func (e objectEntry) ModTime() time.Time         { return e.modTime }
+func (e objectEntry) Sys() interface{}           { return nil }
+func (e objectEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+func (e objectEntry) Type() fs.FileMode {
+	return e.Mode().Type()
+}
+
+func (e objectEntry) Mode() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func (o objectFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	prefix := ""
+	if name != "." && name != "" {
+		prefix = strings.TrimSuffix(name, "/") + "/"
+	}
+	entries := []fs.DirEntry{}
+	for object := range o.client.ListObjects(context.Background(), o.bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		entryName := strings.TrimPrefix(object.Key, prefix)
+		switch {
+		case entryName == "":
+			// the "directory" placeholder object some tools create
+			continue
+		case strings.HasSuffix(entryName, "/"):
+			entries = append(entries, objectEntry{name: strings.TrimSuffix(entryName, "/"), dir: true})
+		default:
+			entries = append(entries, objectEntry{name: entryName, size: object.Size, modTime: object.LastModified})
+		}
+	}
+	return entries, nil
+}
+
+// object keys use "/" whatever the host OS
+func (objectFileSystem) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (o objectFileSystem) Open(name string) (File, error) {
+	object, err := o.client.GetObject(context.Background(), o.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return objectFile{object, name}, nil
+}
+
+type objectFile struct {
+	*minio.Object
+	name string
+}
+
+func (f objectFile) Stat() (fs.FileInfo, error) {
+	info, err := f.Object.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return objectEntry{name: path.Base(f.name), size: info.Size, modTime: info.LastModified}, nil
+}
+
+// openObjectStore connects to s3://bucket/prefix or gs://bucket/prefix.
+//
+// Credentials come from the usual AWS sources (environment, ~/.aws/credentials, instance role).
+// AWS_ENDPOINT_URL points s3:// at another S3-compatible service; gs:// talks to Google Cloud
+// Storage's S3-compatible API, which takes HMAC keys in AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY.
+func openObjectStore(location string) (FileSystem, string, func(), error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return nil, "", nil, fmt.Errorf("invalid bucket location %s", location)
+	}
+
+	endpoint, secure := S3_ENDPOINT, true
+	if u.Scheme == "gs" {
+		endpoint = GCS_ENDPOINT
+	} else if custom := os.Getenv("AWS_ENDPOINT_URL"); custom != "" {
+		endpointURL, err := url.Parse(custom)
+		if err != nil || endpointURL.Host == "" {
+			return nil, "", nil, fmt.Errorf("invalid AWS_ENDPOINT_URL %s", custom)
+		}
+		endpoint, secure = endpointURL.Host, endpointURL.Scheme != "http"
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds: credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{},
+		}),
+		Secure: secure,
+		Region: os.Getenv("AWS_REGION"),
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error connecting to %s: %v", endpoint, err)
+	}
+	logger.Info().Msg("🪣 Listing bucket " + u.Host + " on " + endpoint)
+
+	root := strings.Trim(u.Path, "/")
+	if root == "" {
+		root = "."
+	}
+	return objectFileSystem{client: client, bucket: u.Host}, root, func() {}, nil
+}
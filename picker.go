@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const PICKER_HEIGHT = 12
+const PICKER_CHROME = 5
+
+// newDirectoryPicker returns a file picker that browses directories, starting from the
+// typed path when it is a directory and the working directory otherwise.
+func newDirectoryPicker(typed string, hidden bool, height int) filepicker.Model {
+	picker := filepicker.New()
+	picker.DirAllowed = true
+	picker.FileAllowed = false
+	picker.ShowHidden = hidden
+	picker.AutoHeight = false
+	picker.Height = pickerHeight(height)
+
+	start, _ := os.Getwd()
+	if info, err := os.Stat(normalizeLocalPath(typed)); typed != "" && err == nil && info.IsDir() {
+		start = normalizeLocalPath(typed)
+	}
+	if abs, err := filepath.Abs(start); err == nil {
+		start = abs
+	}
+	picker.CurrentDirectory = start
+	return picker
+}
+
+// pickerHeight fits the picker in a terminal of the given height, 0 meaning unknown.
+func pickerHeight(height int) int {
+	if height == 0 {
+		return PICKER_HEIGHT
+	}
+	// leave room for the title and the help
+	if height-PICKER_CHROME < MIN_RESULTS_HEIGHT {
+		return MIN_RESULTS_HEIGHT
+	}
+	return height - PICKER_CHROME
+}
+
+// updatePicker handles messages while the directory picker is open. Picking a directory
+// puts it in the prompt, so it can still be edited before starting the scan.
+func (m Model) updatePicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(keyMsg, keys.Quit):
+			return m, tea.Quit
+		case key.Matches(keyMsg, keys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+			return m, nil
+		case key.Matches(keyMsg, keys.Log):
+			m.showLog = !m.showLog
+			return m, nil
+		case key.Matches(keyMsg, keys.ClosePicker):
+			m.picking = false
+			return m, textinput.Blink
+		case key.Matches(keyMsg, keys.PickHere):
+			return m.pickDirectory(m.picker.CurrentDirectory)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
+	if selected, dirPath := m.picker.DidSelectFile(msg); selected {
+		return m.pickDirectory(dirPath)
+	}
+	return m, cmd
+}
+
+func (m Model) pickDirectory(dirPath string) (tea.Model, tea.Cmd) {
+	m.picking = false
+	m.textInput.SetValue(dirPath)
+	m.textInput.CursorEnd()
+	return m, textinput.Blink
+}
+
+func (m Model) pickerView() string {
+	return "Browse to the directory to scan: " + m.picker.CurrentDirectory + "\n\n" + m.picker.View()
+}
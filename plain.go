@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/pterm/pterm"
+)
+
+// plainMode renders the TUI without colors, emoji or box drawing, for screen readers
+// and limited terminals. It follows the NO_COLOR convention (https://no-color.org).
+var plainMode bool
+
+func addNoColorFlag(flags *flag.FlagSet) {
+	flags.BoolVar(&plainMode, "no-color", false, "plain ASCII output without colors or banner, also enabled by NO_COLOR")
+}
+
+// enablePlainMode switches every style and symbol of the TUI to plain ASCII.
+func enablePlainMode() {
+	plainMode = true
+	pterm.DisableStyling()
+	lipgloss.SetColorProfile(termenv.Ascii)
+	styles = Styles{
+		Spinner:  lipgloss.NewStyle(),
+		Selected: lipgloss.NewStyle(),
+		Status:   lipgloss.NewStyle(),
+		Error:    lipgloss.NewStyle(),
+	}
+	for _, binding := range []*key.Binding{
+		&keys.RecentUp, &keys.RecentDown, &keys.Previous, &keys.Next,
+		&keys.ListUp, &keys.ListDown, &keys.MenuUp, &keys.MenuDown,
+	} {
+		h := binding.Help()
+		binding.SetHelp(asciiArrows(h.Key), h.Desc)
+	}
+}
+
+// noColorRequested reports whether NO_COLOR is set to a non-empty value.
+func noColorRequested() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// symbol returns fancy, or its ASCII replacement in plain mode.
+func symbol(fancy string, ascii string) string {
+	if plainMode {
+		return ascii
+	}
+	return fancy
+}
+
+func asciiArrows(s string) string {
+	return strings.NewReplacer("↑", "up", "↓", "down", "←", "left", "→", "right").Replace(s)
+}
+
+func newHelp() help.Model {
+	h := help.New()
+	if plainMode {
+		h.ShortSeparator = " | "
+		h.Ellipsis = "..."
+	}
+	return h
+}
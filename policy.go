@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CoveragePolicy is the coverage a directory has to keep, for requiring i18n one part of
+// the code base at a time. MinCoverage is a percentage, "80%" or 80.
+type CoveragePolicy struct {
+	MinCoverage string `yaml:"min_coverage"`
+
+	min float64
+}
+
+// parsePolicies checks the policies of the config, keyed by directory relative to the root,
+// and returns them keyed by their cleaned slash-separated directory.
+func parsePolicies(policies map[string]CoveragePolicy) (map[string]CoveragePolicy, error) {
+	parsed := map[string]CoveragePolicy{}
+	for directory, policy := range policies {
+		value := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(policy.MinCoverage), "%"))
+		min, err := strconv.ParseFloat(value, 64)
+		if err != nil || min < 0 || min > 100 {
+			return nil, fmt.Errorf("%s: invalid min_coverage %q, expected a percentage like 80%%", directory, policy.MinCoverage)
+		}
+		policy.min = min
+		parsed[path.Clean(strings.ReplaceAll(directory, "\\", "/"))] = policy
+	}
+	return parsed, nil
+}
+
+// PolicyResult is the coverage of a directory with a policy, against its minimum.
+type PolicyResult struct {
+	Coverage
+	MinCoverage float64 `json:"min_coverage"`
+}
+
+// Violated tells whether the directory is below its minimum. A directory without candidate
+// files has nothing to translate.
+func (p PolicyResult) Violated() bool {
+	return p.Candidates > 0 && p.Percent() < p.MinCoverage
+}
+
+// countPolicies records a candidate file, at rel from the root, against the coverage of
+// every policy directory holding it. The caller holds s.mu.
+func (s *Scanner) countPolicies(rel string, matches int) {
+	for directory := range s.options.Policies {
+		if directory != "." && rel != directory && !strings.HasPrefix(rel, directory+"/") {
+			continue
+		}
+		if s.policyCoverage == nil {
+			s.policyCoverage = map[string]*Coverage{}
+		}
+		c, ok := s.policyCoverage[directory]
+		if !ok {
+			c = &Coverage{Directory: directory}
+			s.policyCoverage[directory] = c
+		}
+		c.Candidates++
+		if matches > 0 {
+			c.Matched++
+			c.Matches += matches
+		}
+	}
+}
+
+// Policies returns the coverage of every policy directory, in natural order of directory.
+func (s *Scanner) Policies() []PolicyResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := []PolicyResult{}
+	for directory, policy := range s.options.Policies {
+		c := Coverage{Directory: directory}
+		if counted, ok := s.policyCoverage[directory]; ok {
+			c = *counted
+		}
+		results = append(results, PolicyResult{Coverage: c, MinCoverage: policy.min})
+	}
+	sort.Slice(results, func(i, j int) bool { return naturalLess(results[i].Directory, results[j].Directory) })
+	return results
+}
+
+// PolicyViolations lists the directories below their minimum coverage.
+func (r Report) PolicyViolations() []PolicyResult {
+	violations := []PolicyResult{}
+	for _, p := range r.Policies {
+		if p.Violated() {
+			violations = append(violations, p)
+		}
+	}
+	return violations
+}
+
+// policyViolations describes the violations, a line per directory.
+func policyViolations(violations []PolicyResult) string {
+	var out strings.Builder
+	out.WriteString("Coverage policy violations:\n")
+	for _, p := range violations {
+		fmt.Fprintf(&out, "  %s: %.1f%% of files with translations, %.1f%% required (%d of %d)\n", p.Directory, p.Percent(), p.MinCoverage, p.Matched, p.Candidates)
+	}
+	return out.String()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+)
+
+// Profiles holds the profiling flags shared by every mode.
+type Profiles struct {
+	cpuProfile string
+	memProfile string
+}
+
+func addProfileFlags(flags *flag.FlagSet) *Profiles {
+	p := &Profiles{}
+	flags.StringVar(&p.cpuProfile, "cpuprofile", "", "write a CPU profile to this file")
+	flags.StringVar(&p.memProfile, "memprofile", "", "write a heap profile to this file on exit")
+	return p
+}
+
+// start begins CPU profiling if requested; the returned function stops it and writes the heap profile.
+func (p *Profiles) start() (func(), error) {
+	var cpuFile *os.File
+	if p.cpuProfile != "" {
+		f, err := os.Create(p.cpuProfile)
+		if err != nil {
+			return nil, fmt.Errorf("error creating CPU profile: %v", err)
+		}
+		if err := runtimepprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("error starting CPU profile: %v", err)
+		}
+		cpuFile = f
+	}
+
+	return func() {
+		if cpuFile != nil {
+			runtimepprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+		if p.memProfile != "" {
+			f, err := os.Create(p.memProfile)
+			if err != nil {
+				logger.Error().Msg("error creating heap profile: " + err.Error())
+				return
+			}
+			defer f.Close()
+			// get up-to-date statistics
+			runtime.GC()
+			if err := runtimepprof.WriteHeapProfile(f); err != nil {
+				logger.Error().Msg("error writing heap profile: " + err.Error())
+			}
+		}
+	}, nil
+}
+
+// registerPprof exposes the net/http/pprof endpoints under /debug/pprof/.
+func registerPprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const CATALOG_PROPERTIES = "properties"
+
+// propertiesLocale is the locale of a resource bundle from its name, messages_fr_CA.properties
+// holding fr_CA. The base bundle, without a suffix, has the empty locale. ARB files are
+// named the same way, app_fr.arb.
+func propertiesLocale(catalogPath string) string {
+	name := strings.TrimSuffix(filepath.Base(catalogPath), filepath.Ext(catalogPath))
+	if i := strings.IndexByte(name, '_'); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
+
+// latin1 decodes ISO-8859-1, where every byte is the code point of the same value.
+func latin1(contents []byte) string {
+	runes := make([]rune, len(contents))
+	for i, b := range contents {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// parseProperties reads a .properties resource bundle. Like Java 9 and later, a file that
+// is valid UTF-8 is read as such, anything else as ISO-8859-1, with \uXXXX escapes in both.
+func (c *Catalog) parseProperties(contents []byte) error {
+	text := string(contents)
+	if !utf8.Valid(contents) {
+		text = latin1(contents)
+	}
+	// a file already holding UTF-8 is written back as such, any other with escapes
+	c.ascii = !utf8.Valid(contents) || !hasNonASCII(contents)
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(text, "\n")
+
+	// comment lines right above a key describe it; a block at the top followed by a
+	// blank line is the header of the file
+	comment := []string{}
+	for i := 0; i < len(lines); i++ {
+		start := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" {
+			if len(c.Keys) == 0 && c.header == "" && len(comment) > 0 {
+				c.header = strings.Join(comment, "\n")
+			}
+			comment = comment[:0]
+			continue
+		}
+		if line[0] == '#' || line[0] == '!' {
+			comment = append(comment, strings.TrimPrefix(strings.TrimRight(line[1:], " \t\f"), " "))
+			continue
+		}
+		// an odd number of trailing backslashes continues the line on the next one
+		for endsWithContinuation(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if endsWithContinuation(line) {
+			line = line[:len(line)-1]
+		}
+
+		keyEnd := len(line)
+		for j := 0; j < len(line); j++ {
+			if line[j] == '\\' {
+				j++
+				continue
+			}
+			if strings.IndexByte("=: \t\f", line[j]) >= 0 {
+				keyEnd = j
+				break
+			}
+		}
+		rest := strings.TrimLeft(line[keyEnd:], " \t\f")
+		if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+			rest = strings.TrimLeft(rest[1:], " \t\f")
+		}
+		key, err := unescapeProperties(line[:keyEnd])
+		if err != nil {
+			return fmt.Errorf("line %d: %v", start, err)
+		}
+		value, err := unescapeProperties(rest)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", start, err)
+		}
+		c.add(Message{Key: key, Text: value, Line: start, Description: strings.Join(comment, "\n")})
+		comment = comment[:0]
+	}
+	return nil
+}
+
+func hasNonASCII(contents []byte) bool {
+	for _, b := range contents {
+		if b >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+func endsWithContinuation(line string) bool {
+	backslashes := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+func unescapeProperties(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			out.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			out.WriteByte('\t')
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 'f':
+			out.WriteByte('\f')
+		case 'u':
+			code, ok := hexEscape(s, i+1)
+			if !ok {
+				return "", fmt.Errorf("malformed \\u escape")
+			}
+			i += 4
+			// characters outside the BMP are written as a pair of escaped UTF-16 halves
+			if utf16.IsSurrogate(code) && i+2 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+				if low, ok := hexEscape(s, i+3); ok {
+					if combined := utf16.DecodeRune(code, low); combined != utf8.RuneError {
+						code = combined
+						i += 6
+					}
+				}
+			}
+			out.WriteRune(code)
+		default:
+			out.WriteByte(s[i])
+		}
+	}
+	return out.String(), nil
+}
+
+// hexEscape reads the four hex digits of a \u escape starting at s[i].
+func hexEscape(s string, i int) (rune, bool) {
+	if i+4 > len(s) {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(s[i:i+4], 16, 16)
+	return rune(code), err == nil
+}
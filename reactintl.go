@@ -0,0 +1,71 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+const REACT_INTL_DEFINE = `\bdefineMessages?\(`
+const REACT_INTL_FORMAT = `\bformatMessage\(`
+
+var reactIntlDefine = regexp.MustCompile(REACT_INTL_DEFINE)
+var reactIntlFormat = regexp.MustCompile(REACT_INTL_FORMAT)
+
+// extractReactIntl reads the message descriptors of react-intl: the ones of defineMessages
+// ({greeting: {id, defaultMessage}}), defineMessage and intl.formatMessage({id, ...}).
+// formatMessage calls given a variable, like messages.greeting, are found through the
+// defineMessages declaring it.
+func extractReactIntl(filePath string, contents string) []ExtractedMessage {
+	messages := []ExtractedMessage{}
+	if !strings.Contains(contents, "efineMessage") && !strings.Contains(contents, "formatMessage") {
+		return messages
+	}
+	descriptor := func(object string, offset int) {
+		id, _ := objectString(object, "id")
+		text, _ := objectString(object, "defaultMessage")
+		description, _ := objectString(object, "description")
+		// Lingui's defineMessage takes message and comment, its id defaulting to the message
+		if lingui, ok := objectString(object, "message"); ok && text == "" {
+			text = lingui
+			if id == "" {
+				id = lingui
+			}
+		}
+		if comment, ok := objectString(object, "comment"); ok && description == "" {
+			description = comment
+		}
+		if id == "" {
+			return
+		}
+		line := lineAt(contents, offset)
+		if i := strings.Index(contents[offset:], object); i >= 0 {
+			line = lineAt(contents, offset+i)
+		}
+		messages = append(messages, ExtractedMessage{ID: id, Text: text, Description: description, Path: filePath, Line: line})
+	}
+	for _, match := range reactIntlDefine.FindAllStringIndex(contents, -1) {
+		args := callArguments(contents, match[1]-1)
+		if len(args) == 0 {
+			continue
+		}
+		if strings.HasPrefix(contents[match[0]:match[1]], "defineMessages") {
+			object := strings.TrimSpace(args[0])
+			if !strings.HasPrefix(object, "{") || !strings.HasSuffix(object, "}") {
+				continue
+			}
+			for _, property := range callArguments("("+object[1:len(object)-1]+")", 0) {
+				if colon := strings.IndexByte(property, ':'); colon >= 0 {
+					descriptor(strings.TrimSpace(property[colon+1:]), match[1])
+				}
+			}
+		} else {
+			descriptor(strings.TrimSpace(args[0]), match[1])
+		}
+	}
+	for _, match := range reactIntlFormat.FindAllStringIndex(contents, -1) {
+		if args := callArguments(contents, match[1]-1); len(args) > 0 {
+			descriptor(strings.TrimSpace(args[0]), match[1])
+		}
+	}
+	return messages
+}
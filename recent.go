@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const STATE_DIRECTORY_NAME = "dirwalker"
+const RECENT_FILE_NAME = "recent"
+const RECENT_LIMIT = 50
+const RECENT_SUGGESTIONS = 5
+
+// stateDirectory is where dirwalker keeps what it remembers between runs.
+func stateDirectory() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error locating state directory: %v", err)
+	}
+	return filepath.Join(configDir, STATE_DIRECTORY_NAME), nil
+}
+
+// loadRecentDirectories returns the previously scanned locations, most recent first.
+func loadRecentDirectories() ([]string, error) {
+	dir, err := stateDirectory()
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, RECENT_FILE_NAME))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("error reading recent directories: %v", err)
+	}
+	recent := []string{}
+	for _, line := range strings.Split(string(contents), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			recent = append(recent, line)
+		}
+	}
+	return recent, nil
+}
+
+// rememberDirectory moves location to the front of the recent list and saves it.
+func rememberDirectory(recent []string, location string) ([]string, error) {
+	updated := []string{location}
+	for _, r := range recent {
+		if r != location && len(updated) < RECENT_LIMIT {
+			updated = append(updated, r)
+		}
+	}
+
+	dir, err := stateDirectory()
+	if err != nil {
+		return updated, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return updated, fmt.Errorf("error saving recent directories: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, RECENT_FILE_NAME), []byte(strings.Join(updated, "\n")+"\n"), 0644); err != nil {
+		return updated, fmt.Errorf("error saving recent directories: %v", err)
+	}
+	return updated, nil
+}
+
+// recentSuggestions returns up to limit recent locations starting with what was typed so far, 0 meaning all.
+func recentSuggestions(recent []string, typed string, limit int) []string {
+	suggestions := []string{}
+	for _, r := range recent {
+		if r != typed && strings.HasPrefix(r, typed) {
+			suggestions = append(suggestions, r)
+			if len(suggestions) == limit {
+				break
+			}
+		}
+	}
+	return suggestions
+}
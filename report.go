@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+const FORMAT_TEXT = "text"
+const FORMAT_JSON = "json"
+const FORMAT_MARKDOWN = "markdown"
+const FORMAT_HTML = "html"
+const FORMAT_GITHUB = "github"
+const FORMAT_TAP = "tap"
+const FORMAT_XLSX = "xlsx"
+const FORMAT_COVERAGE = "coverage"
+const FORMAT_AUTHORS = "authors"
+const FORMAT_AGE = "age"
+
+// Report is the outcome of a headless scan, rendered in one of the output formats.
+type Report struct {
+	Location     string        `json:"location"`
+	ScannedAt    time.Time     `json:"scanned_at"`
+	Duration     time.Duration `json:"duration"`
+	FilesScanned int64         `json:"files_scanned"`
+	FilesMatched int64         `json:"files_matched"`
+	Matches      int64         `json:"matches"`
+	FilesSkipped int64         `json:"files_skipped"`
+	FilesIgnored int64         `json:"files_ignored"`
+	Suppressed   int64         `json:"suppressed"`
+	LimitReached bool          `json:"limit_reached"`
+	FoundFiles   []string      `json:"found_files"`
+	Coverage     []Coverage    `json:"coverage"`
+	FailedFiles  []FailedFile  `json:"failed_files"`
+	// BOMFiles start with a UTF-8 byte order mark, better saved without.
+	BOMFiles []string `json:"bom_files"`
+	// Findings are the matched lines with the rule that matched them.
+	Findings []Match `json:"findings,omitempty"`
+	// Policies are the coverages of the directories with a minimum in the config.
+	Policies []PolicyResult `json:"policies,omitempty"`
+	// FileMatches are the matches of every found file.
+	FileMatches map[string]int `json:"-"`
+	// TopDirectories is how many directories the text summary charts, 0 for none.
+	TopDirectories int `json:"-"`
+}
+
+func newReport(location string, scanner *Scanner, started time.Time) Report {
+	foundFiles := scanner.foundFiles
+	if foundFiles == nil {
+		foundFiles = []string{}
+	}
+	bomFiles := append([]string{}, scanner.bomFiles...)
+	sortPaths(bomFiles, SORT_NATURAL)
+	return Report{
+		Location:     location,
+		ScannedAt:    started,
+		Duration:     time.Since(started),
+		FilesScanned: scanner.filesScanned.Load(),
+		FilesMatched: scanner.filesMatched.Load(),
+		Matches:      scanner.matchesFound.Load(),
+		FilesSkipped: scanner.filesSkipped.Load(),
+		FilesIgnored: scanner.filesIgnored.Load(),
+		Suppressed:   scanner.matchesSuppressed.Load(),
+		LimitReached: scanner.limitReached.Load(),
+		FoundFiles:   foundFiles,
+		Coverage:     scanner.Coverage(),
+		FailedFiles:  append([]FailedFile{}, scanner.failedFiles...),
+		BOMFiles:     bomFiles,
+		Policies:     scanner.Policies(),
+		FileMatches:  scanner.fileMatches,
+	}
+}
+
+// validReportFormat checks the value given to -format.
+func validReportFormat(format string) error {
+	switch format {
+	case FORMAT_TEXT, FORMAT_JSON, FORMAT_MARKDOWN, FORMAT_HTML, FORMAT_GITHUB, FORMAT_TAP, FORMAT_XLSX, FORMAT_COVERAGE, FORMAT_AUTHORS, FORMAT_AGE:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join([]string{
+		FORMAT_TEXT, FORMAT_JSON, FORMAT_MARKDOWN, FORMAT_HTML, FORMAT_GITHUB, FORMAT_TAP, FORMAT_XLSX, FORMAT_COVERAGE, FORMAT_AUTHORS, FORMAT_AGE,
+	}, ", "))
+}
+
+// RuleCount is how many lines and files a rule matched.
+type RuleCount struct {
+	Rule  string
+	Lines int
+	Files int
+}
+
+// fileRules lists the rules that matched in every file, by name. A finding resolved
+// through a source map counts for its source and for the bundle it was found in.
+func (r Report) fileRules() map[string][]string {
+	seen := map[string]map[string]bool{}
+	rules := map[string][]string{}
+	add := func(path, rule string) {
+		if seen[path] == nil {
+			seen[path] = map[string]bool{}
+		}
+		if !seen[path][rule] {
+			seen[path][rule] = true
+			rules[path] = append(rules[path], rule)
+		}
+	}
+	for _, finding := range r.Findings {
+		add(finding.Path, finding.Rule)
+		if finding.Bundle != "" {
+			add(bundlePath(finding.Bundle), finding.Rule)
+		}
+	}
+	for _, names := range rules {
+		sort.Strings(names)
+	}
+	return rules
+}
+
+// RuleCounts counts the matched lines and files of every rule, the rules matching the
+// most lines first.
+func (r Report) RuleCounts() []RuleCount {
+	byRule := map[string]*RuleCount{}
+	for _, finding := range r.Findings {
+		if byRule[finding.Rule] == nil {
+			byRule[finding.Rule] = &RuleCount{Rule: finding.Rule}
+		}
+		byRule[finding.Rule].Lines++
+	}
+	for _, rules := range r.fileRules() {
+		for _, rule := range rules {
+			byRule[rule].Files++
+		}
+	}
+	counts := []RuleCount{}
+	for _, c := range byRule {
+		counts = append(counts, *c)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Lines != counts[j].Lines {
+			return counts[i].Lines > counts[j].Lines
+		}
+		return counts[i].Rule < counts[j].Rule
+	})
+	return counts
+}
+
+// FileRules is a file with translation content and the rules that matched in it.
+type FileRules struct {
+	Path  string
+	Rules string
+}
+
+// FilesWithRules lists the files found with the rules that matched in them.
+func (r Report) FilesWithRules() []FileRules {
+	rules := r.fileRules()
+	files := make([]FileRules, len(r.FoundFiles))
+	for i, name := range r.FoundFiles {
+		files[i] = FileRules{Path: name, Rules: strings.Join(rules[name], ", ")}
+	}
+	return files
+}
+
+func (r Report) render(format string) (string, error) {
+	switch format {
+	case FORMAT_JSON:
+		contents, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding report: %v", err)
+		}
+		return string(contents) + "\n", nil
+	case FORMAT_MARKDOWN:
+		return r.markdown(), nil
+	case FORMAT_HTML:
+		return r.html()
+	case FORMAT_GITHUB:
+		return r.githubAnnotations(), nil
+	case FORMAT_TAP:
+		return r.tap(), nil
+	case FORMAT_XLSX:
+		return r.xlsx()
+	case FORMAT_COVERAGE:
+		return r.coverageTable()
+	case FORMAT_AUTHORS:
+		return r.authorsTable()
+	case FORMAT_AGE:
+		return r.ageReport()
+	}
+	return r.text(), nil
+}
+
+func (r Report) text() string {
+	var out strings.Builder
+	for _, file := range r.FilesWithRules() {
+		if file.Rules == "" {
+			out.WriteString(file.Path + "\n")
+		} else {
+			fmt.Fprintf(&out, "%s [%s]\n", file.Path, file.Rules)
+		}
+	}
+	for _, failed := range r.FailedFiles {
+		fmt.Fprintf(&out, "%s: %s\n", failed.Path, failed.Error)
+	}
+	for _, name := range r.BOMFiles {
+		fmt.Fprintf(&out, "%s: starts with a byte order mark\n", name)
+	}
+	fmt.Fprintf(&out, "%d files found with translation content (%d scanned in %s).\n", len(r.FoundFiles), r.FilesScanned, r.Duration.Round(time.Millisecond))
+	if r.LimitReached {
+		out.WriteString("The scan stopped early after reaching the maximum number of matches.\n")
+	}
+	if r.FilesSkipped > 0 {
+		fmt.Fprintf(&out, "%d generated files skipped.\n", r.FilesSkipped)
+	}
+	if r.FilesIgnored > 0 || r.Suppressed > 0 {
+		fmt.Fprintf(&out, "%d matches suppressed and %d files ignored by inline directives.\n", r.Suppressed, r.FilesIgnored)
+	}
+	if counts := r.RuleCounts(); len(counts) > 0 {
+		out.WriteString("\nMatches per rule:\n")
+		for _, c := range counts {
+			fmt.Fprintf(&out, "%s: %d lines in %d files\n", c.Rule, c.Lines, c.Files)
+		}
+	}
+	out.WriteString(r.hotSpots())
+	return out.String()
+}
+
+// counts lists the matches of every file found, then their total, like wc: "3 src/app.js".
+func (r Report) counts() string {
+	var out strings.Builder
+	for _, name := range r.FoundFiles {
+		fmt.Fprintf(&out, "%d %s\n", r.FileMatches[name], name)
+	}
+	fmt.Fprintf(&out, "%d total\n", r.Matches)
+	return out.String()
+}
+
+// vimgrep lists the matched lines as ripgrep's --vimgrep does, path:line:col:text, for
+// Vim's quickfix list (:cexpr system(...) with grepformat %f:%l:%c:%m) and editors alike.
+func (r Report) vimgrep() string {
+	var out strings.Builder
+	for _, finding := range r.Findings {
+		fmt.Fprintf(&out, "%s:%d:%d:%s\n", workspacePath(finding.Path), finding.Line, finding.Col, finding.Snippet)
+	}
+	return out.String()
+}
+
+func (r Report) Title() string {
+	return "Localization report for " + r.Location
+}
+
+// Percent is the share of candidate files in the directory with translation content.
+func (c Coverage) Percent() float64 {
+	if c.Candidates == 0 {
+		return 0
+	}
+	return 100 * float64(c.Matched) / float64(c.Candidates)
+}
+
+func (r Report) markdown() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "# %s\n\n", r.Title())
+	fmt.Fprintf(&out, "Scanned %d files in %s on %s: %d files with translation content, %d matches.\n\n",
+		r.FilesScanned, r.Duration.Round(time.Millisecond), r.ScannedAt.Format(time.RFC1123), len(r.FoundFiles), r.Matches)
+	if len(r.Coverage) > 0 {
+		out.WriteString("## Coverage\n\n| Directory | Files | With translations | Coverage |\n|---|---:|---:|---:|\n")
+		for _, c := range r.Coverage {
+			fmt.Fprintf(&out, "| %s | %d | %d | %.1f%% |\n", markdownEscape(c.Directory), c.Candidates, c.Matched, c.Percent())
+		}
+		out.WriteString("\n")
+	}
+	if counts := r.RuleCounts(); len(counts) > 0 {
+		out.WriteString("## Rules\n\n| Rule | Lines | Files |\n|---|---:|---:|\n")
+		for _, c := range counts {
+			fmt.Fprintf(&out, "| %s | %d | %d |\n", markdownEscape(c.Rule), c.Lines, c.Files)
+		}
+		out.WriteString("\n")
+	}
+	if len(r.FoundFiles) > 0 {
+		out.WriteString("## Files\n\n")
+		for _, file := range r.FilesWithRules() {
+			if file.Rules == "" {
+				fmt.Fprintf(&out, "- `%s`\n", file.Path)
+			} else {
+				fmt.Fprintf(&out, "- `%s`: %s\n", file.Path, markdownEscape(file.Rules))
+			}
+		}
+		out.WriteString("\n")
+	}
+	if len(r.FailedFiles) > 0 {
+		out.WriteString("## Unreadable files\n\n")
+		for _, failed := range r.FailedFiles {
+			fmt.Fprintf(&out, "- `%s`: %s\n", failed.Path, failed.Error)
+		}
+		out.WriteString("\n")
+	}
+	if len(r.BOMFiles) > 0 {
+		out.WriteString("## Files with a byte order mark\n\n")
+		for _, name := range r.BOMFiles {
+			fmt.Fprintf(&out, "- `%s`\n", name)
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`).Replace(s)
+}
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; }
+td.number { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Scanned {{.FilesScanned}} files in {{.Duration}} on {{.ScannedAt.Format "Mon, 02 Jan 2006 15:04:05 MST"}}:
+{{len .FoundFiles}} files with translation content, {{.Matches}} matches.</p>
+{{if .Coverage}}<h2>Coverage</h2>
+<table>
+<tr><th>Directory</th><th>Files</th><th>With translations</th><th>Coverage</th></tr>
+{{range .Coverage}}<tr><td>{{.Directory}}</td><td class="number">{{.Candidates}}</td><td class="number">{{.Matched}}</td><td class="number">{{printf "%.1f" .Percent}}%</td></tr>
+{{end}}</table>
+{{end}}{{with .RuleCounts}}<h2>Rules</h2>
+<table>
+<tr><th>Rule</th><th>Lines</th><th>Files</th></tr>
+{{range .}}<tr><td>{{.Rule}}</td><td class="number">{{.Lines}}</td><td class="number">{{.Files}}</td></tr>
+{{end}}</table>
+{{end}}{{if .FoundFiles}}<h2>Files</h2>
+<ul>
+{{range .FilesWithRules}}<li><code>{{.Path}}</code>{{if .Rules}}: {{.Rules}}{{end}}</li>
+{{end}}</ul>
+{{end}}{{if .FailedFiles}}<h2>Unreadable files</h2>
+<ul>
+{{range .FailedFiles}}<li><code>{{.Path}}</code>: {{.Error}}</li>
+{{end}}</ul>
+{{end}}{{if .BOMFiles}}<h2>Files with a byte order mark</h2>
+<ul>
+{{range .BOMFiles}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+func (r Report) html() (string, error) {
+	r.Duration = r.Duration.Round(time.Millisecond)
+	var out strings.Builder
+	if err := htmlReport.Execute(&out, r); err != nil {
+		return "", fmt.Errorf("error rendering report: %v", err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RESULTS_HEIGHT is the number of results listed until the terminal size is known.
+const RESULTS_HEIGHT = 10
+const MIN_RESULTS_HEIGHT = 3
+
+// RESULTS_CHROME counts the lines around the list: the blank lines before and after it,
+// its position counter, the three footer lines and the blank lines before the help.
+const RESULTS_CHROME = 8
+
+// resultsSummary renders the counts shown above the results list.
+func (m Model) resultsSummary() string {
+	r := m.history[m.current]
+	status := r.Location
+	if len(m.history) > 1 {
+		status = "Scan " + strconv.Itoa(m.current+1) + " of " + strconv.Itoa(len(m.history)) + ": " + r.Location
+	}
+	header := styles.Status.Render(status) + "\n"
+	limitNote := ""
+	if r.LimitReached {
+		limitNote = "The scan stopped early after reaching the maximum number of matches.\n"
+	}
+	if r.NewFiles < len(r.FoundFiles) {
+		limitNote += strconv.Itoa(len(r.FoundFiles)-r.NewFiles) + " of them already found earlier in this session, " + strconv.Itoa(len(m.sessionFiles)) + " distinct files in total.\n"
+	}
+	if r.FilesSkipped > 0 {
+		limitNote += strconv.FormatInt(r.FilesSkipped, 10) + " generated files skipped.\n"
+	}
+	if r.FilesIgnored > 0 || r.Suppressed > 0 {
+		limitNote += strconv.FormatInt(r.Suppressed, 10) + " matches suppressed and " + strconv.FormatInt(r.FilesIgnored, 10) + " files ignored by inline directives.\n"
+	}
+	if r.FilesWithBOM > 0 {
+		limitNote += strconv.Itoa(r.FilesWithBOM) + " files start with a byte order mark.\n"
+	}
+	if reviewed, ignored := m.marks.counts(r.FoundFiles); reviewed > 0 || ignored > 0 {
+		limitNote += strconv.Itoa(reviewed) + " reviewed and " + strconv.Itoa(ignored) + " ignored, " + strconv.Itoa(len(r.FoundFiles)-reviewed-ignored) + " left to triage.\n"
+	}
+	if len(r.Failed) > 0 {
+		limitNote += strconv.Itoa(len(r.Failed)) + " files or folders could not be read"
+		if m.showFailed {
+			limitNote += ", listed below.\n"
+		} else {
+			limitNote += ", press f to review and retry them.\n"
+		}
+	}
+	return header + strconv.Itoa(len(r.FoundFiles)) + " files found with translation content.\n" + limitNote
+}
+
+// listItems are the entries of the results list: the found files, or the failed ones
+// while that panel is shown.
+func (m Model) listItems() []string {
+	r := m.history[m.current]
+	if m.showMatches {
+		items := []string{}
+		for _, match := range r.Matches {
+			items = append(items, match.String())
+		}
+		return items
+	}
+	if !m.showFailed {
+		return r.FoundFiles
+	}
+	items := []string{}
+	for _, f := range r.Failed {
+		items = append(items, f.Path+" ("+f.Error+")")
+	}
+	return items
+}
+
+// resultsList renders the page of list entries around the selected one.
+func (m Model) resultsList() string {
+	files := m.listItems()
+	if len(files) == 0 {
+		return ""
+	}
+	var list strings.Builder
+	list.WriteString("\n")
+	height := m.listHeight()
+	for i := m.offset; i < len(files) && i < m.offset+height; i++ {
+		entry := truncatePath(files[i], m.width-2)
+		if !m.showFailed && !m.showMatches {
+			badge := m.marks.badge(files[i])
+			entry = badge + truncatePath(files[i], m.width-2-len([]rune(badge)))
+		}
+		if i == m.selected {
+			list.WriteString(styles.Selected.Render("> "+entry) + "\n")
+		} else {
+			list.WriteString("  " + entry + "\n")
+		}
+	}
+	if len(files) > height {
+		list.WriteString("  (" + strconv.Itoa(m.selected+1) + "/" + strconv.Itoa(len(files)) + ")\n")
+	}
+	list.WriteString("\n")
+	return list.String()
+}
+
+// moveSelection moves the highlighted entry by delta, scrolling to keep it in view.
+func (m Model) moveSelection(delta int) Model {
+	if m.err != nil || len(m.history) == 0 {
+		return m
+	}
+	files := m.listItems()
+	m.selected += delta
+	if m.selected > len(files)-1 {
+		m.selected = len(files) - 1
+	}
+	if m.selected < 0 {
+		m.selected = 0
+	}
+	if m.selected < m.offset {
+		m.offset = m.selected
+	}
+	if height := m.listHeight(); m.selected >= m.offset+height {
+		m.offset = m.selected - height + 1
+	}
+	return m
+}
+
+// updateMouse scrolls the results list with the wheel and selects the clicked entry.
+func (m Model) updateMouse(msg tea.MouseMsg) Model {
+	switch msg.Type {
+	case tea.MouseWheelUp:
+		return m.moveSelection(-1)
+	case tea.MouseWheelDown:
+		return m.moveSelection(1)
+	case tea.MouseLeft:
+		// the list starts after the summary and its leading blank line
+		row := msg.Y - strings.Count(m.resultsSummary(), "\n") - 1
+		if row >= 0 && row < m.listHeight() && m.offset+row < len(m.listItems()) {
+			m.selected = m.offset + row
+		}
+	}
+	return m
+}
+
+// listHeight is how many results fit in the terminal below the summary.
+func (m Model) listHeight() int {
+	if m.height == 0 {
+		return RESULTS_HEIGHT
+	}
+	used := strings.Count(m.resultsSummary(), "\n") + RESULTS_CHROME + lipgloss.Height(m.help.View(m.screenKeys()))
+	if m.showLog {
+		used += lipgloss.Height(m.logPane()) + 1
+	}
+	if m.height-used < MIN_RESULTS_HEIGHT {
+		return MIN_RESULTS_HEIGHT
+	}
+	return m.height - used
+}
+
+// truncatePath shortens a path to width characters, keeping its end where the file name is.
+func truncatePath(filePath string, width int) string {
+	ellipsis := symbol("…", "...")
+	runes := []rune(filePath)
+	if width <= 0 || len(runes) <= width || width <= len([]rune(ellipsis)) {
+		return filePath
+	}
+	return ellipsis + string(runes[len(runes)-width+len([]rune(ellipsis)):])
+}
+
+// Retried is the outcome of retrying failed files of the scan at index Scan of the history.
+type Retried struct {
+	Scan    int
+	Results Results
+	// Paths are the files that were retried.
+	Paths []string
+}
+
+// retryFiles reads failed files of the shown scan again.
+func (m Model) retryFiles(failed []FailedFile) tea.Cmd {
+	scan := m.current
+	location := m.history[scan].Location
+	paths := []string{}
+	for _, f := range failed {
+		paths = append(paths, f.Path)
+	}
+	return func() tea.Msg {
+		scanner := NewScanner(m.options)
+		matches := collectMatches(scanner)
+		if err := scanner.rescan(location, failed); err != nil {
+			return Results{Err: err}
+		}
+		return Retried{
+			Scan:  scan,
+			Paths: paths,
+			Results: Results{
+				FoundFiles:   scanner.foundFiles,
+				Matches:      *matches,
+				FilesSkipped: scanner.filesSkipped.Load(),
+				FilesIgnored: scanner.filesIgnored.Load(),
+				Suppressed:   scanner.matchesSuppressed.Load(),
+				FilesWithBOM: len(scanner.bomFiles),
+				Failed:       scanner.failedFiles,
+				Coverage:     scanner.Coverage(),
+			},
+		}
+	}
+}
+
+// mergeRetried folds the retried files into the results of their scan.
+func (m Model) mergeRetried(msg Retried) Model {
+	r := m.history[msg.Scan]
+	retried := map[string]bool{}
+	for _, filePath := range msg.Paths {
+		retried[filePath] = true
+	}
+	failed := []FailedFile{}
+	for _, f := range r.Failed {
+		if !retried[f.Path] {
+			failed = append(failed, f)
+		}
+	}
+	r.Failed = append(failed, msg.Results.Failed...)
+
+	found := map[string]bool{}
+	for _, filePath := range r.FoundFiles {
+		found[filePath] = true
+	}
+	r.FoundFiles = append([]string{}, r.FoundFiles...)
+	for _, filePath := range msg.Results.FoundFiles {
+		if found[filePath] {
+			continue
+		}
+		r.FoundFiles = append(r.FoundFiles, filePath)
+		if !m.sessionFiles[filePath] {
+			m.sessionFiles[filePath] = true
+			r.NewFiles++
+		}
+	}
+	sortPaths(r.FoundFiles, m.options.Sort)
+	r.Matches = sortFindings(append(r.Matches, msg.Results.Matches...), m.options.Sort)
+	r.FilesSkipped += msg.Results.FilesSkipped
+	r.FilesIgnored += msg.Results.FilesIgnored
+	r.Suppressed += msg.Results.Suppressed
+	r.FilesWithBOM += msg.Results.FilesWithBOM
+	r.Coverage = mergeCoverage(r.Coverage, msg.Results.Coverage)
+
+	m.history[msg.Scan] = r
+	if len(r.Failed) == 0 {
+		m.showFailed = false
+	}
+	m.showMatches = false
+	m.selected, m.offset = 0, 0
+	return m
+}
+
+// mergeCoverage adds the coverage of retried files to that of their scan.
+func mergeCoverage(coverage []Coverage, retried []Coverage) []Coverage {
+	merged := append([]Coverage{}, coverage...)
+	for _, c := range retried {
+		found := false
+		for i := range merged {
+			if merged[i].Directory == c.Directory {
+				merged[i].Candidates += c.Candidates
+				merged[i].Matched += c.Matched
+				merged[i].Matches += c.Matches
+				found = true
+			}
+		}
+		if !found {
+			merged = append(merged, c)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return naturalLess(merged[i].Directory, merged[j].Directory) })
+	return merged
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+const DEFAULT_READ_RETRIES = 2
+const DEFAULT_RETRY_BACKOFF = 100 * time.Millisecond
+
+// TRANSIENT_ERRORS are the errors worth a retry: interrupted calls, a busy resource and the
+// hiccups of network file systems.
+var TRANSIENT_ERRORS = []error{syscall.EINTR, syscall.EAGAIN, syscall.ETIMEDOUT, syscall.ESTALE, syscall.EIO}
+
+// isTransient tells whether a read failing with err may succeed when tried again.
+func isTransient(err error) bool {
+	for _, transient := range TRANSIENT_ERRORS {
+		if errors.Is(err, transient) {
+			return true
+		}
+	}
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// withRetries runs read until it succeeds, fails for good or has been retried
+// options.ReadRetries times, waiting twice as long before every retry.
+func (s *Scanner) withRetries(filePath string, read func() error) error {
+	backoff := s.options.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := read()
+		if err == nil || attempt >= s.options.ReadRetries || !isTransient(err) {
+			return err
+		}
+		s.log.Warn().Msg("🔁 Retrying file in " + backoff.String() + " after " + err.Error() + " → " + filePath)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
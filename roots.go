@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const ROOT_PROGRESS_INTERVAL = 200 * time.Millisecond
+
+// rootScan is the scan of one of several roots scanned together.
+type rootScan struct {
+	location string
+	scanner  *Scanner
+	started  time.Time
+	done     bool
+	err      error
+}
+
+// scanRoots scans the locations concurrently, each with a scanner of its own set up by
+// setup, showing a progress line per root on an interactive terminal. The scanners share
+// a throttle, for -read-rate to hold for all of them. With -nice or -max-memory the roots
+// are scanned one after another, each having all of one file at a time or of the budget.
+func scanRoots(locations []string, options ScanOptions, setup func(*Scanner)) []*rootScan {
+	scans := make([]*rootScan, len(locations))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, location := range locations {
+		scans[i] = &rootScan{location: location, scanner: NewScanner(options), started: time.Now()}
+		scans[i].scanner.throttle = scans[0].scanner.throttle
+		setup(scans[i].scanner)
+	}
+	run := func(scan *rootScan) {
+		mu.Lock()
+		scan.started = time.Now()
+		mu.Unlock()
+		err := scan.scanner.scan(scan.location)
+		mu.Lock()
+		scan.done, scan.err = true, err
+		mu.Unlock()
+	}
+	if options.Nice || options.MaxMemory > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, scan := range scans {
+				run(scan)
+			}
+		}()
+	} else {
+		for _, scan := range scans {
+			wg.Add(1)
+			go func(scan *rootScan) {
+				defer wg.Done()
+				run(scan)
+			}(scan)
+		}
+	}
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	interactive := len(scans) > 1 && !plainMode && term.IsTerminal(int(os.Stderr.Fd()))
+	if interactive {
+		ticker := time.NewTicker(ROOT_PROGRESS_INTERVAL)
+		defer ticker.Stop()
+		for drawn := false; ; drawn = true {
+			select {
+			case <-finished:
+				mu.Lock()
+				drawRootProgress(scans, drawn)
+				mu.Unlock()
+				return scans
+			case <-ticker.C:
+				mu.Lock()
+				drawRootProgress(scans, drawn)
+				mu.Unlock()
+			}
+		}
+	}
+	<-finished
+	return scans
+}
+
+// drawRootProgress writes a line per root on the standard error, over the previous ones.
+func drawRootProgress(scans []*rootScan, redraw bool) {
+	var out strings.Builder
+	if redraw {
+		fmt.Fprintf(&out, "\x1b[%dA", len(scans))
+	}
+	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	for _, scan := range scans {
+		status := frames[int(time.Since(scan.started)/ROOT_PROGRESS_INTERVAL)%len(frames)]
+		if scan.err != nil {
+			status = "✗"
+		} else if scan.done {
+			status = "✓"
+		}
+		fmt.Fprintf(&out, "\x1b[2K%s %s: %d files scanned, %d with translation content\n", status, scan.location, scan.scanner.filesScanned.Load(), scan.scanner.filesMatched.Load())
+	}
+	os.Stderr.WriteString(out.String())
+}
+
+// mergeReports combines the reports of several roots, the files of overlapping ones
+// listed once, in the order. Their coverage directories are prefixed with the root they're in.
+func mergeReports(reports []Report, order string) Report {
+	merged := Report{FoundFiles: []string{}, Coverage: []Coverage{}, FailedFiles: []FailedFile{}, BOMFiles: []string{}, FileMatches: map[string]int{}}
+	locations := []string{}
+	for i, r := range reports {
+		locations = append(locations, r.Location)
+		if i == 0 || r.ScannedAt.Before(merged.ScannedAt) {
+			merged.ScannedAt = r.ScannedAt
+		}
+		if r.Duration > merged.Duration {
+			merged.Duration = r.Duration
+		}
+		merged.FilesScanned += r.FilesScanned
+		merged.FilesMatched += r.FilesMatched
+		merged.Matches += r.Matches
+		merged.FilesSkipped += r.FilesSkipped
+		merged.FilesIgnored += r.FilesIgnored
+		merged.Suppressed += r.Suppressed
+		merged.LimitReached = merged.LimitReached || r.LimitReached
+		merged.FoundFiles = append(merged.FoundFiles, r.FoundFiles...)
+		merged.FailedFiles = append(merged.FailedFiles, r.FailedFiles...)
+		merged.BOMFiles = append(merged.BOMFiles, r.BOMFiles...)
+		merged.Findings = append(merged.Findings, r.Findings...)
+		for name, matches := range r.FileMatches {
+			merged.FileMatches[name] = matches
+		}
+		for _, c := range r.Coverage {
+			c.Directory = path.Join(r.Location, c.Directory)
+			merged.Coverage = append(merged.Coverage, c)
+		}
+		for _, p := range r.Policies {
+			p.Directory = path.Join(r.Location, p.Directory)
+			merged.Policies = append(merged.Policies, p)
+		}
+	}
+	merged.FoundFiles = uniquePaths(merged.FoundFiles)
+	sortPaths(merged.FoundFiles, order)
+	merged.BOMFiles = uniquePaths(merged.BOMFiles)
+	sortPaths(merged.BOMFiles, SORT_NATURAL)
+	merged.Location = strings.Join(locations, ", ")
+	merged.Coverage = mergeCoverage(merged.Coverage, nil)
+	return merged
+}
+
+// uniquePaths drops the repeated paths, keeping the first of each.
+func uniquePaths(paths []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rule is a pattern marking translated content.
+type Rule struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+	// CaseInsensitive also matches the pattern written with different casing, e.g. DATA-MC-TRANSLATE.
+	CaseInsensitive bool `yaml:"case_insensitive"`
+	// WholeToken only matches the pattern as a whole token/attribute, so data-mc-translate-ignore
+	// is not counted as data-mc-translate.
+	WholeToken bool `yaml:"whole_token"`
+	// Suppress turns the rule into an inline opt-out: findings on lines matching it are dropped.
+	Suppress bool `yaml:"suppress"`
+	// Expression combines patterns instead of a single Pattern, e.g. `"<Message" AND NOT "defaultMessage"`.
+	// It is evaluated per line and counts one match per matching line.
+	Expression string `yaml:"expression"`
+	// Heuristic runs a built-in detector over the whole file instead of matching a pattern:
+	// hardcoded-text flags the JSX and HTML text left untranslated.
+	Heuristic string `yaml:"heuristic"`
+	// Regexp is a regular expression matched instead of a plain Pattern.
+	Regexp string `yaml:"regexp"`
+	// Scope limits the rule to the attributes, text, script or style of HTML files and vue
+	// templates, to count the markers of inline JavaScript and of markup apart.
+	Scope string `yaml:"scope"`
+
+	expr exprNode
+	re   *regexp.Regexp
+}
+
+var DEFAULT_RULES = []Rule{
+	{Name: "data-mc-translate", Pattern: DATA_MC_TRANSLATE},
+	{Name: "message-id", Pattern: MESSAGE_ID},
+	// angular-translate: {{ 'KEY' | translate }}, <h1 translate="KEY"> and $translate.instant('KEY')
+	regexpRule("translate-pipe", `\|\s*translate\b`),
+	regexpRule("translate-directive", `<[A-Za-z][^<>]*\s(?:translate|translate-attr-[\w-]+)(?:\s*=\s*["'][^"']*[A-Z_.{][^"']*["']|\s*/?>|\s)`),
+	regexpRule("translate-service", `\$translate(?:\.instant)?\(`),
+	// i18next: t('key'), i18n.t('key') and the react-i18next hooks
+	regexpRule("i18next-t", I18NEXT_CALL),
+	regexpRule("i18next-hook", `\b(?:useTranslation|withTranslation)\(`),
+	// react-intl: <FormattedMessage>, defineMessages({...}) and intl.formatMessage({...})
+	regexpRule("formatted-message", `<Formatted(?:HTML)?Message\b`),
+	regexpRule("define-messages", REACT_INTL_DEFINE),
+	regexpRule("format-message", REACT_INTL_FORMAT),
+	// Lingui: the t and msg macros, <Trans> and i18n._(...)
+	regexpRule("lingui-macro", LINGUI_MACRO),
+	regexpRule("trans-component", `<Trans\b`),
+	regexpRule("lingui-i18n", `\bi18n\._\(`),
+	// vue-i18n: $t('key'), $tc('key', n), v-t="'key'" and the <i18n> blocks of components
+	regexpRule("vue-t", VUE_T),
+	regexpRule("vue-t-directive", VUE_T_DIRECTIVE),
+	regexpRule("vue-i18n-block", `<i18n\b`),
+}
+
+// regexpRule makes a built-in rule matching a regular expression.
+func regexpRule(name string, expression string) Rule {
+	return Rule{Name: name, Regexp: expression, re: regexp.MustCompile(expression)}
+}
+
+// effectiveRules merges the configured rules into the defaults. A configured rule
+// named like a default one overrides it, keeping the default pattern when none is given.
+func effectiveRules(configured []Rule) ([]Rule, error) {
+	rules := append([]Rule{}, DEFAULT_RULES...)
+	for _, rule := range configured {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule with pattern %q has no name", rule.Pattern)
+		}
+		overridden := false
+		for i, existing := range rules {
+			if existing.Name == rule.Name {
+				if rule.Pattern == "" && rule.Expression == "" && rule.Regexp == "" {
+					rule.Pattern = existing.Pattern
+					rule.Regexp = existing.Regexp
+				}
+				rules[i] = rule
+				overridden = true
+			}
+		}
+		if rule.Pattern != "" && rule.Expression != "" {
+			return nil, fmt.Errorf("rule %s has both a pattern and an expression", rule.Name)
+		}
+		if rule.Regexp != "" && (rule.Pattern != "" || rule.Expression != "") {
+			return nil, fmt.Errorf("rule %s has both a regexp and a pattern", rule.Name)
+		}
+		if rule.Heuristic != "" {
+			if rule.Pattern != "" || rule.Expression != "" || rule.Regexp != "" {
+				return nil, fmt.Errorf("rule %s has both a heuristic and a pattern", rule.Name)
+			}
+			if rule.Suppress {
+				return nil, fmt.Errorf("rule %s suppresses with a heuristic, suppressions need a pattern", rule.Name)
+			}
+			if !validHeuristic(rule.Heuristic) {
+				return nil, fmt.Errorf("rule %s has an unknown heuristic %q, expected %s", rule.Name, rule.Heuristic, HEURISTIC_HARDCODED_TEXT)
+			}
+		} else if rule.Expression != "" {
+			expr, err := parseExpression(rule.Expression)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+			}
+			rule.expr = expr
+		} else if rule.Pattern == "" && rule.Regexp == "" {
+			return nil, fmt.Errorf("rule %s has no pattern", rule.Name)
+		}
+		if rule.Scope != "" && (rule.Heuristic != "" || !validScope(rule.Scope)) {
+			return nil, fmt.Errorf("rule %s has an invalid scope %q, expected %s, %s, %s or %s on a pattern", rule.Name, rule.Scope, SCOPE_ATTRIBUTE, SCOPE_TEXT, SCOPE_SCRIPT, SCOPE_STYLE)
+		}
+		if !overridden {
+			rules = append(rules, rule)
+		}
+	}
+	// compiled last, so overriding case_insensitive applies to a built-in regexp too
+	for i, rule := range rules {
+		if rule.Regexp == "" {
+			continue
+		}
+		expression := rule.Regexp
+		if rule.CaseInsensitive {
+			expression = "(?i)" + expression
+		}
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid regexp: %v", rule.Name, err)
+		}
+		rules[i].re = re
+	}
+	return rules, nil
+}
+
+// IGNORE_FILE_DIRECTIVE anywhere in a file, e.g. "// dirwalker:ignore-file", drops all of its findings.
+const IGNORE_FILE_DIRECTIVE = "dirwalker:ignore-file"
+
+// IGNORE_NEXT_LINE_DIRECTIVE drops the findings on the following line, e.g. "<!-- dirwalker:ignore-next-line -->".
+const IGNORE_NEXT_LINE_DIRECTIVE = "dirwalker:ignore-next-line"
+
+// SNIPPET_LENGTH is the most characters of the matched line a Match keeps.
+const SNIPPET_LENGTH = 120
+
+// Match is a line of a file matched by a rule or an analyzer. Col is the 1-based byte column
+// of the first match on the line, or of its text for heuristics and expressions. Key is the
+// message key used on the line, when there's one an extractor reads. Bundle is the
+// path:line:col of a match in a bundle whose source map located it in the source file.
+type Match struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Col     int    `json:"col"`
+	Rule    string `json:"rule"`
+	Snippet string `json:"snippet"`
+	Key     string `json:"key,omitempty"`
+	Bundle  string `json:"bundle,omitempty"`
+
+	// analyzer is the command of the external analyzer that found the match, for the log
+	analyzer string
+}
+
+// String is the match as grep -n shows it, path:line:col, with its rule and snippet.
+func (m Match) String() string {
+	return fmt.Sprintf("%s:%d:%d [%s] %s", m.Path, m.Line, m.Col, m.Rule, m.Snippet)
+}
+
+// snippet is the text of a matched line without its indentation, shortened to SNIPPET_LENGTH.
+func snippet(line string) string {
+	text := strings.TrimSpace(line)
+	if runes := []rune(text); len(runes) > SNIPPET_LENGTH {
+		return string(runes[:SNIPPET_LENGTH]) + "…"
+	}
+	return text
+}
+
+// textColumn is the column of the first non-blank character of a line.
+func textColumn(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t")) + 1
+}
+
+// countMatches counts the rule matches in contents, stopping once limit is reached (0 means no limit).
+// Matches on lines opted out by a suppression rule or an ignore-next-line directive are not counted
+// but returned as suppressed. onMatch, when set, is called for every line a rule matched, with
+// the path and key left for the caller. ignoreComments leaves out the matches in comments.
+func countMatches(rules []Rule, contents string, limit int, ignoreComments bool, onMatch func(match Match)) (int, int) {
+	suppressions, patterns := []Rule{}, []Rule{}
+	for _, rule := range rules {
+		if rule.Suppress {
+			suppressions = append(suppressions, rule)
+		} else {
+			patterns = append(patterns, rule)
+		}
+	}
+
+	// heuristics look at the whole file, their hits are then counted line by line
+	hits := map[string]map[int]int{}
+	for _, rule := range patterns {
+		if rule.Heuristic != "" {
+			hits[rule.Name] = heuristicLines(rule.Heuristic, contents)
+		}
+	}
+
+	// scoped rules only count the matches in the regions of their scope, and none count
+	// the matches in comments when they're ignored
+	var regions, comments []markupRegion
+	for _, rule := range patterns {
+		if rule.Scope != "" {
+			regions = markupRegions(contents)
+			break
+		}
+	}
+	if ignoreComments {
+		comments = commentRegions(contents)
+	}
+	lineStart := 0
+	keep := func(rule Rule) func(offset int) bool {
+		if rule.Scope == "" && len(comments) == 0 {
+			return nil
+		}
+		start := lineStart
+		return func(offset int) bool {
+			return (rule.Scope == "" || scopeAt(regions, start+offset) == rule.Scope) && scopeAt(comments, start+offset) == ""
+		}
+	}
+
+	count, suppressedCount := 0, 0
+	ignoreNext := false
+	rest := contents
+	lineNumber := 0
+	for rest != "" {
+		lineNumber++
+		lineStart = len(contents) - len(rest)
+		line := rest
+		if i := strings.IndexByte(rest, '\n'); i >= 0 {
+			line, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = ""
+		}
+
+		lowered := ""
+		suppressed := ignoreNext
+		ignoreNext = strings.Contains(line, IGNORE_NEXT_LINE_DIRECTIVE)
+		for _, rule := range suppressions {
+			if suppressed {
+				break
+			}
+			found, _ := rule.occurrences(line, &lowered, 1, nil)
+			suppressed = found > 0
+		}
+		for _, rule := range patterns {
+			if rule.Heuristic != "" {
+				found := hits[rule.Name][lineNumber]
+				if suppressed {
+					suppressedCount += found
+					continue
+				}
+				if limit > 0 && count+found > limit {
+					found = limit - count
+				}
+				if found > 0 && onMatch != nil {
+					onMatch(Match{Line: lineNumber, Col: textColumn(line), Rule: rule.Name, Snippet: snippet(line)})
+				}
+				count += found
+				if limit > 0 && count >= limit {
+					return count, suppressedCount
+				}
+				continue
+			}
+			if suppressed {
+				found, _ := rule.occurrences(line, &lowered, 0, keep(rule))
+				suppressedCount += found
+				continue
+			}
+			remaining := 0
+			if limit > 0 {
+				remaining = limit - count
+			}
+			found, first := rule.occurrences(line, &lowered, remaining, keep(rule))
+			if found > 0 && onMatch != nil {
+				onMatch(Match{Line: lineNumber, Col: first + 1, Rule: rule.Name, Snippet: snippet(line)})
+			}
+			count += found
+			if limit > 0 && count >= limit {
+				return count, suppressedCount
+			}
+		}
+	}
+	return count, suppressedCount
+}
+
+// occurrences counts the rule's matches in text, stopping once limit is reached (0 means no limit),
+// and tells the offset of the first one. lowered caches the lower-cased text between rules.
+// Only the matches at an offset keep accepts are counted, when it's set.
+func (rule Rule) occurrences(text string, lowered *string, limit int, keep func(offset int) bool) (int, int) {
+	if rule.expr != nil {
+		if rule.expr.eval(Rule{CaseInsensitive: rule.CaseInsensitive, WholeToken: rule.WholeToken}, text, lowered) && (keep == nil || keep(textColumn(text)-1)) {
+			return 1, textColumn(text) - 1
+		}
+		return 0, -1
+	}
+	if rule.re != nil {
+		count, first := 0, -1
+		for _, match := range rule.re.FindAllStringIndex(text, -1) {
+			if match[0] == match[1] || (rule.WholeToken && !isWholeToken(text, match[0], match[1])) {
+				continue
+			}
+			// patterns like I18NEXT_CALL start with the character before the call
+			matched := text[match[0]:match[1]]
+			start := match[0] + len(matched) - len(strings.TrimLeft(matched, " \t"))
+			if keep != nil && !keep(start) {
+				continue
+			}
+			if count == 0 {
+				first = start
+			}
+			count++
+			if limit > 0 && count >= limit {
+				break
+			}
+		}
+		return count, first
+	}
+	haystack, needle := text, rule.Pattern
+	if needle == "" {
+		// an empty pattern would be found everywhere
+		return 0, -1
+	}
+	if rule.CaseInsensitive {
+		if *lowered == "" {
+			*lowered = strings.ToLower(text)
+		}
+		haystack, needle = *lowered, strings.ToLower(needle)
+	}
+	count, first := 0, -1
+	offset := 0
+	for {
+		i := strings.Index(haystack[offset:], needle)
+		if i < 0 {
+			return count, first
+		}
+		start, end := offset+i, offset+i+len(needle)
+		offset = end
+		if (rule.WholeToken && !isWholeToken(haystack, start, end)) || (keep != nil && !keep(start)) {
+			continue
+		}
+		if count == 0 {
+			first = start
+		}
+		count++
+		if limit > 0 && count >= limit {
+			return count, first
+		}
+	}
+}
+
+// isTokenChar reports whether c can be part of an identifier or attribute name.
+func isTokenChar(c byte) bool {
+	return c == '-' || c == '_' || c == ':' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// isWholeToken reports whether text[start:end] isn't glued to surrounding token characters.
+// Edges of the pattern that aren't token characters themselves (like the "<" of "<Message")
+// already delimit the match and are not checked.
+func isWholeToken(text string, start int, end int) bool {
+	if isTokenChar(text[start]) && start > 0 && isTokenChar(text[start-1]) {
+		return false
+	}
+	if isTokenChar(text[end-1]) && end < len(text) && isTokenChar(text[end]) {
+		return false
+	}
+	return true
+}
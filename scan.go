@@ -0,0 +1,200 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+// runScan scans a directory without the TUI and writes the report, for scripts and CI.
+func runScan(args []string) {
+	flags := flag.NewFlagSet("scan", flag.ExitOnError)
+	var analyzerCommands stringList
+	flags.Var(&analyzerCommands, "analyzer", "command starting an external gRPC analyzer (repeatable)")
+	profiles := addProfileFlags(flags)
+	options := addScanFlags(flags)
+	configPath := addConfigFlag(flags)
+	addNoColorFlag(flags)
+	format := flags.String("format", FORMAT_TEXT, "report format: text, json, markdown, html, github (workflow command annotations), tap, xlsx, coverage (per directory table), authors (git blame leaderboard) or age (of the matched lines)")
+	outputPath := flags.String("output", "", "write the report to this file instead of the standard output")
+	emailRecipients := flags.String("email-report", "", "comma separated addresses the report is emailed to, through the smtp settings of the config")
+	githubIssues := flags.Bool("github-issues", false, "open or update a GitHub issue per file with findings, in the github repository of the config")
+	jiraIssues := flags.Bool("jira-issues", false, "open or update a Jira issue per file with findings, in the jira project of the config")
+	top := flags.Int("top", DEFAULT_TOP_DIRECTORIES, "directories with the most matches charted in the text summary (0 for none)")
+	keepHistory := flags.Bool("history", false, "store the scan in the history, for `dirwalker trend`")
+	notifyWebhooks := flags.Bool("notify", false, "post the summary of the scan to the webhooks of the config, with the files new or gone since the previous scan in the history")
+	filesWithoutMatch := flags.Bool("files-without-match", false, "list the candidate files without any translation content instead of the report")
+	filesWithMatches := flags.Bool("files-with-matches", false, "list just the files with translation content instead of the report")
+	countOnly := flags.Bool("count", false, "print the matches of every file with translation content and their total instead of the report")
+	vimgrep := flags.Bool("vimgrep", false, "print every matched line as path:line:col:text, like ripgrep --vimgrep, instead of the report")
+	flags.Parse(args)
+
+	if err := validReportFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	modes := 0
+	for _, mode := range []bool{*filesWithoutMatch, *filesWithMatches, *countOnly, *vimgrep} {
+		if mode {
+			modes++
+		}
+	}
+	if modes > 1 {
+		fmt.Fprintln(os.Stderr, "-count, -files-with-matches, -files-without-match and -vimgrep can't be combined")
+		os.Exit(2)
+	}
+
+	scanRun = true
+	setupLogger()
+	if err := applyConfig(*configPath, options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// several directories, or the roots of the config, are scanned concurrently
+	locations := flags.Args()
+	if len(locations) == 0 {
+		for _, root := range config.Roots {
+			locations = append(locations, expandHome(root))
+		}
+	}
+	if len(locations) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker scan [-format text|json|markdown|html|github|tap|xlsx|coverage|authors|age] [-output file] [-email-report addresses] [-count|-files-with-matches|-files-without-match|-vimgrep] <directory>...")
+		os.Exit(2)
+	}
+	if len(locations) > 1 && options.Checkpoint != "" {
+		fmt.Fprintln(os.Stderr, "-checkpoint takes a single directory")
+		os.Exit(2)
+	}
+	for _, location := range locations {
+		if err := validateLocation(location); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if plainMode || noColorRequested() {
+		enablePlainMode()
+	} else if *outputPath != "" || !term.IsTerminal(int(os.Stdout.Fd())) {
+		// colors would end up as escape codes in the file or the next program's input
+		pterm.DisableStyling()
+	}
+	stopProfiling, err := profiles.start()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopProfiling()
+	if err := startAnalyzers(analyzerCommands); err != nil {
+		stopProfiling()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopAnalyzers()
+
+	var mu sync.Mutex
+	findings := []Match{}
+	unmatched := []string{}
+	setup := func(scanner *Scanner) {
+		scanner.onFinding = func(finding Match) {
+			mu.Lock()
+			findings = append(findings, finding)
+			mu.Unlock()
+		}
+		if *filesWithoutMatch {
+			scanner.onMiss = func(filePath string) {
+				mu.Lock()
+				unmatched = append(unmatched, filePath)
+				mu.Unlock()
+			}
+		}
+	}
+	reports := []Report{}
+	for _, scan := range scanRoots(locations, *options, setup) {
+		if scan.err != nil {
+			stopAnalyzers()
+			stopProfiling()
+			fmt.Fprintln(os.Stderr, scan.err)
+			os.Exit(1)
+		}
+		reports = append(reports, newReport(scan.location, scan.scanner, scan.started))
+	}
+	if *notifyWebhooks && len(config.Webhooks) == 0 {
+		logger.Warn().Msg("🔕 -notify without webhooks in the config")
+	}
+	if *keepHistory || *notifyWebhooks {
+		for _, report := range reports {
+			entry := HistoryEntry{
+				Root:         historyRoot(report.Location),
+				ScannedAt:    report.ScannedAt,
+				Duration:     report.Duration,
+				FilesScanned: report.FilesScanned,
+				Matches:      report.Matches,
+				FoundFiles:   report.FoundFiles,
+				Coverage:     report.Coverage,
+			}
+			if *notifyWebhooks {
+				notifyScan(entry)
+			}
+			if !*keepHistory {
+				continue
+			}
+			if err := appendHistory(entry); err != nil {
+				logger.Error().Msg(err.Error())
+			}
+		}
+	}
+	report := reports[0]
+	if len(reports) > 1 {
+		report = mergeReports(reports, options.Sort)
+	}
+	report.TopDirectories = *top
+	report.Findings = sortFindings(findings, options.Sort)
+
+	var output string
+	if *filesWithoutMatch {
+		sortPaths(unmatched, options.Sort)
+		output = strings.Join(append(unmatched, ""), "\n")
+	} else if *filesWithMatches {
+		output = strings.Join(append(append([]string{}, report.FoundFiles...), ""), "\n")
+	} else if *countOnly {
+		output = report.counts()
+	} else if *vimgrep {
+		output = report.vimgrep()
+	} else {
+		output, err = report.render(*format)
+	}
+	if err == nil {
+		if *outputPath != "" {
+			err = os.WriteFile(*outputPath, []byte(output), 0644)
+		} else {
+			_, err = os.Stdout.WriteString(output)
+		}
+	}
+	if err == nil && *emailRecipients != "" {
+		err = emailReport(config.SMTP, parseRecipients(*emailRecipients), report)
+	}
+	if err == nil && *githubIssues {
+		err = fileGitHubIssues(config.GitHub, report)
+	}
+	if err == nil && *jiraIssues {
+		err = fileJiraIssues(config.Jira, report)
+	}
+	if err != nil {
+		logger.Error().Msg(err.Error())
+		stopAnalyzers()
+		stopProfiling()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if violations := report.PolicyViolations(); len(violations) > 0 {
+		logger.Warn().Msg(fmt.Sprintf("📉 %d directories below their minimum coverage", len(violations)))
+		stopAnalyzers()
+		stopProfiling()
+		fmt.Fprint(os.Stderr, policyViolations(violations))
+		os.Exit(1)
+	}
+}
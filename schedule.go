@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule is a root the daemon scans on its own, at the times given by a
+// standard five-field cron expression ("0 * * * *" is hourly).
+type Schedule struct {
+	Root string `yaml:"root"`
+	Cron string `yaml:"cron"`
+}
+
+// startSchedules runs the configured scheduled scans until the returned function is called.
+func (d *Daemon) startSchedules(schedules []Schedule) (func(), error) {
+	c := cron.New()
+	for _, schedule := range schedules {
+		root, err := filepath.Abs(expandHome(schedule.Root))
+		if err != nil {
+			return nil, fmt.Errorf("error in schedule for %s: %v", schedule.Root, err)
+		}
+		if _, err := c.AddFunc(schedule.Cron, func() { d.scheduledScan(root) }); err != nil {
+			return nil, fmt.Errorf("error in schedule for %s: %v", schedule.Root, err)
+		}
+		logger.Info().Msg("⏰ Scanning " + root + " on schedule " + schedule.Cron)
+	}
+	c.Start()
+	return func() { <-c.Stop().Done() }, nil
+}
+
+// scheduledScan scans root, stores the result in the history and notifies when the
+// found files changed since the previous stored scan.
+func (d *Daemon) scheduledScan(root string) {
+	previous, err := loadHistory(root)
+	if err != nil {
+		logger.Error().Msg(err.Error())
+	}
+
+	var reply QueryReply
+	if err := d.Scan(QueryArgs{Path: root}, &reply); err != nil {
+		logger.Error().Msg("error in scheduled scan of " + root + ": " + err.Error())
+		return
+	}
+	entry := HistoryEntry{
+		Root:         root,
+		ScannedAt:    reply.StartedAt,
+		Duration:     reply.Duration,
+		FilesScanned: reply.FilesScanned,
+		Matches:      reply.Matches,
+		FoundFiles:   reply.FoundFiles,
+		Coverage:     reply.Coverage,
+	}
+	if err := appendHistory(entry); err != nil {
+		logger.Error().Msg(err.Error())
+	}
+	logger.Info().Msg("⏰ Scheduled scan of " + root + " found " + strconv.Itoa(len(reply.FoundFiles)) + " files")
+
+	if len(previous) == 0 {
+		return
+	}
+	if added, removed := diffFiles(previous[len(previous)-1].FoundFiles, entry.FoundFiles); len(added) > 0 || len(removed) > 0 {
+		notify(Notification{Root: root, Entry: entry, Previous: previous[len(previous)-1], Added: added, Removed: removed})
+	}
+}
+
+// diffFiles returns the files only in after and only in before.
+func diffFiles(before []string, after []string) ([]string, []string) {
+	inBefore := map[string]bool{}
+	for _, f := range before {
+		inBefore[f] = true
+	}
+	inAfter := map[string]bool{}
+	added := []string{}
+	for _, f := range after {
+		inAfter[f] = true
+		if !inBefore[f] {
+			added = append(added, f)
+		}
+	}
+	removed := []string{}
+	for _, f := range before {
+		if !inAfter[f] {
+			removed = append(removed, f)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// The scopes a rule can be limited to in HTML files and the templates of vue components:
+// the attributes of tags, the text between them, and the content of <script> and <style>.
+// JavaScript files are script throughout.
+const SCOPE_ATTRIBUTE = "attribute"
+const SCOPE_TEXT = "text"
+const SCOPE_SCRIPT = "script"
+const SCOPE_STYLE = "style"
+
+// SCOPE_COMMENT is the region of a comment, not a scope rules are limited to.
+const SCOPE_COMMENT = "comment"
+
+func validScope(scope string) bool {
+	return scope == SCOPE_ATTRIBUTE || scope == SCOPE_TEXT || scope == SCOPE_SCRIPT || scope == SCOPE_STYLE
+}
+
+// markupRegion is the bytes [start, end) of a file, in a scope.
+type markupRegion struct {
+	start int
+	end   int
+	scope string
+}
+
+// isMarkup tells an HTML template from JavaScript the way hardcodedText does, by its first tag.
+func isMarkup(contents string) bool {
+	return strings.HasPrefix(strings.TrimSpace(contents), "<")
+}
+
+// markupRegions splits a file into the regions of its scopes and its HTML comments, in
+// order. Tag names and the spaces between attributes are in none.
+func markupRegions(contents string) []markupRegion {
+	if !isMarkup(contents) {
+		return []markupRegion{{start: 0, end: len(contents), scope: SCOPE_SCRIPT}}
+	}
+	regions := []markupRegion{}
+	add := func(start int, end int, scope string) {
+		if end > start {
+			regions = append(regions, markupRegion{start: start, end: end, scope: scope})
+		}
+	}
+	i := 0
+	for i < len(contents) {
+		if strings.HasPrefix(contents[i:], "<!--") {
+			end := strings.Index(contents[i:], "-->")
+			if end < 0 {
+				add(i, len(contents), SCOPE_COMMENT)
+				break
+			}
+			add(i, i+end+len("-->"), SCOPE_COMMENT)
+			i += end + len("-->")
+			continue
+		}
+		if contents[i] != '<' || i+1 == len(contents) || !(isTagStart(contents[i+1]) || contents[i+1] == '/') {
+			start := i
+			for i++; i < len(contents) && !startsTag(contents, i); i++ {
+			}
+			add(start, i, SCOPE_TEXT)
+			continue
+		}
+		name, end := tagAttributes(contents, i, add)
+		i = end
+		if name == SCOPE_SCRIPT || name == SCOPE_STYLE {
+			closing := strings.Index(strings.ToLower(contents[i:]), "</"+name)
+			if closing < 0 {
+				closing = len(contents) - i
+			}
+			add(i, i+closing, name)
+			i += closing
+		}
+	}
+	return regions
+}
+
+func isTagStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// startsTag tells whether a tag or a comment starts at i.
+func startsTag(contents string, i int) bool {
+	return contents[i] == '<' && i+1 < len(contents) && (isTagStart(contents[i+1]) || contents[i+1] == '/' || contents[i+1] == '!')
+}
+
+// tagAttributes reads the tag starting at i, adding the region of every attribute, and
+// returns its lowercased name, empty for a closing tag, and the offset after it.
+func tagAttributes(contents string, i int, add func(start int, end int, scope string)) (string, int) {
+	i++
+	closing := contents[i] == '/'
+	if closing {
+		i++
+	}
+	nameStart := i
+	for i < len(contents) && contents[i] != '>' && contents[i] != '/' && !isSpace(contents[i]) {
+		i++
+	}
+	name := strings.ToLower(contents[nameStart:i])
+	if closing {
+		name = ""
+	}
+	for i < len(contents) && contents[i] != '>' {
+		if isSpace(contents[i]) || contents[i] == '/' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(contents) && contents[i] != '=' && contents[i] != '>' && !isSpace(contents[i]) {
+			i++
+		}
+		if i < len(contents) && contents[i] == '=' {
+			i++
+			if i < len(contents) && (contents[i] == '"' || contents[i] == '\'') {
+				if end := strings.IndexByte(contents[i+1:], contents[i]); end >= 0 {
+					i += end + 2
+				} else {
+					i = len(contents)
+				}
+			} else {
+				for i < len(contents) && contents[i] != '>' && !isSpace(contents[i]) {
+					i++
+				}
+			}
+		}
+		add(start, i, SCOPE_ATTRIBUTE)
+	}
+	if i < len(contents) {
+		i++
+	}
+	return name, i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// scopeAt is the scope of the region holding offset, empty out of any.
+func scopeAt(regions []markupRegion, offset int) string {
+	i := sort.Search(len(regions), func(i int) bool { return regions[i].end > offset })
+	if i < len(regions) && regions[i].start <= offset {
+		return regions[i].scope
+	}
+	return ""
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const DEFAULT_SERVE_ADDRESS = "127.0.0.1:8080"
+
+const SCAN_RUNNING = "running"
+const SCAN_DONE = "done"
+const SCAN_FAILED = "failed"
+
+// ScanJob is a scan started through the REST API.
+type ScanJob struct {
+	ID         int        `json:"id"`
+	Path       string     `json:"path"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	scanner      *Scanner
+	matchedPaths []string
+}
+
+type ScanStatus struct {
+	ScanJob
+	FilesScanned int64 `json:"files_scanned"`
+	FilesMatched int64 `json:"files_matched"`
+	Matches      int64 `json:"matches"`
+	FilesSkipped int64 `json:"files_skipped"`
+	FilesIgnored int64 `json:"files_ignored"`
+	FilesFailed  int64 `json:"files_failed"`
+	Suppressed   int64 `json:"suppressed"`
+	LimitReached bool  `json:"limit_reached"`
+}
+
+type ScanResults struct {
+	ID           int          `json:"id"`
+	Path         string       `json:"path"`
+	FoundFiles   []string     `json:"found_files"`
+	MatchedPaths []string     `json:"matched_paths"`
+	Coverage     []Coverage   `json:"coverage"`
+	FailedFiles  []FailedFile `json:"failed_files"`
+}
+
+// Server keeps track of the scans triggered over HTTP.
+type Server struct {
+	mu      sync.Mutex
+	nextID  int
+	scans   map[int]*ScanJob
+	options ScanOptions
+}
+
+func NewServer(options ScanOptions) *Server {
+	return &Server{nextID: 1, scans: map[int]*ScanJob{}, options: options}
+}
+
+func (srv *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", webHandler())
+	mux.HandleFunc("/scans", srv.handleScans)
+	mux.HandleFunc("/scans/", srv.handleScan)
+	return mux
+}
+
+func (srv *Server) startScan(dirPath string) *ScanJob {
+	srv.mu.Lock()
+	job := &ScanJob{
+		ID:        srv.nextID,
+		Path:      dirPath,
+		Status:    SCAN_RUNNING,
+		StartedAt: time.Now(),
+		scanner:   NewScanner(srv.options),
+	}
+	job.scanner.onMatch = func(filePath string) {
+		srv.mu.Lock()
+		job.matchedPaths = append(job.matchedPaths, filePath)
+		srv.mu.Unlock()
+	}
+	srv.scans[job.ID] = job
+	srv.nextID++
+	srv.mu.Unlock()
+
+	logger.Info().Msg("🌐 Starting scan " + strconv.Itoa(job.ID) + " of " + dirPath)
+	go func() {
+		err := job.scanner.scan(dirPath)
+
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		finished := time.Now()
+		job.FinishedAt = &finished
+		if err != nil {
+			job.Status = SCAN_FAILED
+			job.Error = err.Error()
+			return
+		}
+		job.Status = SCAN_DONE
+	}()
+	return job
+}
+
+func (srv *Server) status(job *ScanJob) ScanStatus {
+	return ScanStatus{
+		ScanJob:      *job,
+		FilesScanned: job.scanner.filesScanned.Load(),
+		FilesMatched: job.scanner.filesMatched.Load(),
+		Matches:      job.scanner.matchesFound.Load(),
+		FilesSkipped: job.scanner.filesSkipped.Load(),
+		FilesIgnored: job.scanner.filesIgnored.Load(),
+		FilesFailed:  job.scanner.filesFailed.Load(),
+		Suppressed:   job.scanner.matchesSuppressed.Load(),
+		LimitReached: job.scanner.limitReached.Load(),
+	}
+}
+
+// handleScans serves GET /scans (list) and POST /scans (start a scan).
+func (srv *Server) handleScans(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		srv.mu.Lock()
+		statuses := []ScanStatus{}
+		for _, job := range srv.scans {
+			statuses = append(statuses, srv.status(job))
+		}
+		srv.mu.Unlock()
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+		writeJSON(w, http.StatusOK, statuses)
+
+	case http.MethodPost:
+		var request struct {
+			Path string `json:"path"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("error decoding request: %v", err))
+			return
+		}
+		dirPath := strings.TrimSpace(request.Path)
+		if dirPath == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("path is required"))
+			return
+		}
+		if err := validateLocation(dirPath); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		job := srv.startScan(dirPath)
+		srv.mu.Lock()
+		status := srv.status(job)
+		srv.mu.Unlock()
+		writeJSON(w, http.StatusAccepted, status)
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleScan serves GET /scans/{id} (status), GET /scans/{id}/results and the
+// GET /scans/{id}/events WebSocket.
+func (srv *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/scans/"), "/"), "/")
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts) > 2 || (len(parts) == 2 && parts[1] != "results" && parts[1] != "events") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("not found"))
+		return
+	}
+
+	srv.mu.Lock()
+	job, ok := srv.scans[id]
+	if !ok {
+		srv.mu.Unlock()
+		writeError(w, http.StatusNotFound, fmt.Errorf("scan %d not found", id))
+		return
+	}
+	if len(parts) == 2 && parts[1] == "events" {
+		srv.mu.Unlock()
+		srv.streamEvents(w, r, job)
+		return
+	}
+	defer srv.mu.Unlock()
+	if len(parts) == 1 {
+		writeJSON(w, http.StatusOK, srv.status(job))
+		return
+	}
+	if job.Status == SCAN_RUNNING {
+		writeError(w, http.StatusConflict, fmt.Errorf("scan %d is still running", id))
+		return
+	}
+	foundFiles := job.scanner.foundFiles
+	if foundFiles == nil {
+		foundFiles = []string{}
+	}
+	// matchedPaths is in the order files were matched, which the event stream relies on
+	matchedPaths := append([]string{}, job.matchedPaths...)
+	sortPaths(matchedPaths, srv.options.Sort)
+	writeJSON(w, http.StatusOK, ScanResults{
+		ID:           job.ID,
+		Path:         job.Path,
+		FoundFiles:   foundFiles,
+		MatchedPaths: matchedPaths,
+		Coverage:     job.scanner.Coverage(),
+		FailedFiles:  append([]FailedFile{}, job.scanner.failedFiles...),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error().Msg(err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func runServe(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	address := flags.String("addr", DEFAULT_SERVE_ADDRESS, "address to listen on")
+	var analyzerCommands stringList
+	flags.Var(&analyzerCommands, "analyzer", "command starting an external gRPC analyzer (repeatable)")
+	profiles := addProfileFlags(flags)
+	options := addScanFlags(flags)
+	configPath := addConfigFlag(flags)
+	enablePprof := flags.Bool("pprof", false, "expose net/http/pprof endpoints under /debug/pprof/")
+	flags.Parse(args)
+
+	scanRun = true
+	setupLogger()
+	if err := applyConfig(*configPath, options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	stopProfiling, err := profiles.start()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopProfiling()
+	if err := startAnalyzers(analyzerCommands); err != nil {
+		stopProfiling()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopAnalyzers()
+
+	mux := http.NewServeMux()
+	mux.Handle("/", NewServer(*options).Handler())
+	if *enablePprof {
+		registerPprof(mux)
+	}
+	httpServer := &http.Server{Addr: *address, Handler: mux}
+
+	// shut down cleanly on interrupt so profiles get written and analyzers stopped
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		httpServer.Shutdown(context.Background())
+	}()
+
+	logger.Info().Msg("🌐 Serving on " + *address)
+	fmt.Println("Serving on http://" + *address)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Error().Msg(err.Error())
+		stopAnalyzers()
+		stopProfiling()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
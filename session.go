@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SessionExport is written by -export when the TUI exits.
+type SessionExport struct {
+	Scans []Results `json:"scans"`
+	// Files is the combined, de-duplicated list of the files found by every scan.
+	Files []string `json:"files"`
+}
+
+// exportSession writes the results of every scan of the session to a JSON file.
+func exportSession(exportPath string, history []Results, order string) error {
+	export := SessionExport{Scans: history, Files: []string{}}
+	seen := map[string]bool{}
+	for _, r := range history {
+		for _, filePath := range r.FoundFiles {
+			if !seen[filePath] {
+				seen[filePath] = true
+				export.Files = append(export.Files, filePath)
+			}
+		}
+	}
+	sortPaths(export.Files, order)
+	if export.Scans == nil {
+		export.Scans = []Results{}
+	}
+
+	contents, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error exporting session: %v", err)
+	}
+	if err := os.WriteFile(exportPath, append(contents, '\n'), 0644); err != nil {
+		return fmt.Errorf("error exporting session: %v", err)
+	}
+	logger.Info().Msg("💾 Exported " + fmt.Sprint(len(history)) + " scans to " + exportPath)
+	return nil
+}
+
+const SESSION_FILE_NAME = "session.json"
+
+// SavedSession is the TUI state kept on disk so a review can be resumed with -resume.
+type SavedSession struct {
+	SavedAt  time.Time `json:"saved_at"`
+	Scans    []Results `json:"scans"`
+	Current  int       `json:"current"`
+	Selected int       `json:"selected"`
+	// Marks are the marks of the session's files when it was saved.
+	Marks Marks `json:"marks"`
+}
+
+// defaultSessionPath is the session file used unless -session says otherwise.
+func defaultSessionPath() string {
+	dir, err := stateDirectory()
+	if err != nil {
+		return SESSION_FILE_NAME
+	}
+	return filepath.Join(dir, SESSION_FILE_NAME)
+}
+
+// saveSession writes the scans, position and marks of the TUI to the session file.
+func (m Model) saveSession() error {
+	if m.sessionPath == "" || len(m.history) == 0 {
+		return nil
+	}
+	saved := SavedSession{SavedAt: time.Now(), Scans: m.history, Current: m.current, Selected: m.selected, Marks: Marks{}}
+	for _, r := range m.history {
+		for _, filePath := range r.FoundFiles {
+			if mark := m.marks[filePath]; mark != "" {
+				saved.Marks[filePath] = mark
+			}
+		}
+	}
+	contents, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error saving session: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.sessionPath), 0755); err != nil {
+		return fmt.Errorf("error saving session: %v", err)
+	}
+	if err := os.WriteFile(m.sessionPath, contents, 0644); err != nil {
+		return fmt.Errorf("error saving session: %v", err)
+	}
+	return nil
+}
+
+// resumeSession restores the scans and position saved in the session file. Marks made
+// since the session was saved win over the saved ones.
+func (m Model) resumeSession() (Model, error) {
+	contents, err := os.ReadFile(m.sessionPath)
+	if err != nil {
+		return m, fmt.Errorf("error reading session %s: %v", m.sessionPath, err)
+	}
+	var saved SavedSession
+	if err := json.Unmarshal(contents, &saved); err != nil {
+		return m, fmt.Errorf("error parsing session %s: %v", m.sessionPath, err)
+	}
+	if len(saved.Scans) == 0 {
+		return m, nil
+	}
+
+	m.history = saved.Scans
+	for _, r := range m.history {
+		for _, filePath := range r.FoundFiles {
+			m.sessionFiles[filePath] = true
+		}
+	}
+	for filePath, mark := range saved.Marks {
+		if _, ok := m.marks[filePath]; !ok {
+			m.marks[filePath] = mark
+		}
+	}
+	m.current = saved.Current
+	if m.current < 0 || m.current >= len(m.history) {
+		m.current = len(m.history) - 1
+	}
+	m.selected = saved.Selected
+	m.typing, m.choosing = false, false
+	logger.Info().Msg("💾 Resumed " + fmt.Sprint(len(m.history)) + " scans saved at " + saved.SavedAt.Format(time.RFC3339))
+	return m.moveSelection(0), nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const DEFAULT_SSH_PORT = "22"
+
+type sftpFileSystem struct {
+	client *sftp.Client
+}
+
+func (f sftpFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	infos, err := f.client.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	// match os.ReadDir, which returns entries sorted by name
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	return entries, nil
+}
+
+// remote paths always use forward slashes
+func (sftpFileSystem) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (f sftpFileSystem) Open(name string) (File, error) {
+	return f.client.Open(name)
+}
+
+// sshAuthMethods offers the keys of a running ssh-agent, then the default unencrypted key files.
+func sshAuthMethods() []ssh.AuthMethod {
+	methods := []ssh.AuthMethod{}
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	home, _ := os.UserHomeDir()
+	signers := []ssh.Signer{}
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		key, err := os.ReadFile(filepath.Join(home, ".ssh", name))
+		if err != nil {
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			logger.Warn().Msg("Skipping SSH key " + name + ": " + err.Error())
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	return methods
+}
+
+// openSFTP connects to ssh://user@host[:port]/path. Host keys are checked against ~/.ssh/known_hosts.
+// A path starting with /~/ is taken relative to the remote home directory.
+func openSFTP(location string) (FileSystem, string, func(), error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("invalid remote location %s: %v", location, err)
+	}
+	username := u.User.Username()
+	if username == "" {
+		username = os.Getenv("USER")
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), DEFAULT_SSH_PORT)
+	}
+
+	home, _ := os.UserHomeDir()
+	hostKeyCallback, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error reading known_hosts: %v", err)
+	}
+	conn, err := ssh.Dial("tcp", host, &ssh.ClientConfig{
+		User:            username,
+		Auth:            sshAuthMethods(),
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error connecting to %s: %v", host, err)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, "", nil, fmt.Errorf("error starting SFTP on %s: %v", host, err)
+	}
+	logger.Info().Msg("🔐 Connected to " + username + "@" + host)
+
+	root := u.Path
+	if strings.HasPrefix(root, "/~/") {
+		root = strings.TrimPrefix(root, "/~/")
+	} else if root == "" || root == "/~" {
+		root = "."
+	}
+	return sftpFileSystem{client: client}, root, func() {
+		client.Close()
+		conn.Close()
+	}, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const PROBLEM_DUPLICATE_TEXT = "duplicate-text"
+
+// DEFAULT_TEXT_DISTANCE is how many edits apart two default texts may be to be reported.
+const DEFAULT_TEXT_DISTANCE = 2
+
+// levenshtein counts the single character insertions, deletions and substitutions turning
+// a into b.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// similarTexts reports the messages whose default text is the same as, or within distance
+// edits of, the text of an earlier one: candidates for a single shared message, translated
+// once. Case and surrounding whitespace are ignored, and texts shorter than the allowed
+// distance have to be identical.
+func similarTexts(c *Catalog, distance int) []CatalogProblem {
+	problems := []CatalogProblem{}
+	texts := make([][]rune, len(c.Keys))
+	for i, key := range c.Keys {
+		texts[i] = []rune(strings.ToLower(strings.TrimSpace(c.Messages[key].Text)))
+	}
+	for i, key := range c.Keys {
+		if len(texts[i]) == 0 {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if len(texts[j]) == 0 || abs(len(texts[i])-len(texts[j])) > distance {
+				continue
+			}
+			other := c.Keys[j]
+			message := c.Messages[key]
+			edits := 0
+			if string(texts[i]) != string(texts[j]) {
+				if len(texts[i]) <= distance || len(texts[j]) <= distance {
+					continue
+				}
+				if edits = levenshtein(texts[i], texts[j]); edits > distance {
+					continue
+				}
+			}
+			description := "has the same text as " + strconv.Quote(other)
+			if edits > 0 {
+				description = fmt.Sprintf("is %d edits from %q, %q", edits, other, c.Messages[other].Text)
+			}
+			problems = append(problems, CatalogProblem{Path: c.Path, Line: message.Line, Kind: PROBLEM_DUPLICATE_TEXT, Key: key, Message: description})
+			break
+		}
+	}
+	return problems
+}
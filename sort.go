@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const SORT_NATURAL = "natural"
+const SORT_LEXICAL = "lexical"
+const SORT_WALK = "walk"
+
+// validSortOrder checks the value given to -sort, an empty one meaning the default.
+func validSortOrder(order string) error {
+	switch order {
+	case "", SORT_NATURAL, SORT_LEXICAL, SORT_WALK:
+		return nil
+	}
+	return fmt.Errorf("unknown sort order %q, expected %s, %s or %s", order, SORT_NATURAL, SORT_LEXICAL, SORT_WALK)
+}
+
+// sortPaths orders paths in place; the walk order leaves them as found.
+func sortPaths(paths []string, order string) {
+	switch order {
+	case SORT_NATURAL:
+		sort.SliceStable(paths, func(i, j int) bool { return naturalLess(paths[i], paths[j]) })
+	case SORT_LEXICAL:
+		sort.Strings(paths)
+	}
+}
+
+// naturalLess compares strings treating runs of digits as numbers, so "file2" sorts before "file10".
+// Letters compare case-insensitively, falling back to a plain comparison to keep the order total.
+func naturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			startA, startB := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			numberA := strings.TrimLeft(a[startA:i], "0")
+			numberB := strings.TrimLeft(b[startB:j], "0")
+			if len(numberA) != len(numberB) {
+				return len(numberA) < len(numberB)
+			}
+			if numberA != numberB {
+				return numberA < numberB
+			}
+			continue
+		}
+		ca, cb := lowerASCII(a[i]), lowerASCII(b[j])
+		if ca != cb {
+			return ca < cb
+		}
+		i++
+		j++
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	return a < b
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func lowerASCII(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
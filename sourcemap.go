@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const SOURCE_MAP_EXT = ".map"
+
+// VLQ_DIGITS are the base64 digits of the variable-length quantities in source map mappings.
+const VLQ_DIGITS = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+var sourceMappingURL = regexp.MustCompile(`(?m)^\s*(?://|/\*)[#@]\s*sourceMappingURL=(\S+?)\s*(?:\*/)?\s*$`)
+
+// sourceMap maps the lines and columns of a bundle back to the sources it was built from.
+type sourceMap struct {
+	Version    int      `json:"version"`
+	SourceRoot string   `json:"sourceRoot"`
+	Sources    []string `json:"sources"`
+	Mappings   string   `json:"mappings"`
+
+	// lines are the segments of every line of the bundle, by column
+	lines [][]mappingSegment
+}
+
+// mappingSegment is where a column of the bundle comes from, all 0-based.
+type mappingSegment struct {
+	column       int
+	source       int
+	sourceLine   int
+	sourceColumn int
+}
+
+// decodeVLQ decodes the base64 variable-length quantities of a mapping segment.
+func decodeVLQ(field string) ([]int, error) {
+	values := []int{}
+	value, shift := 0, 0
+	for _, c := range field {
+		digit := strings.IndexRune(VLQ_DIGITS, c)
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid mapping %q", field)
+		}
+		value += (digit & 31) << shift
+		if digit&32 != 0 {
+			shift += 5
+			continue
+		}
+		if value&1 != 0 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+		value, shift = 0, 0
+	}
+	if shift != 0 {
+		return nil, fmt.Errorf("truncated mapping %q", field)
+	}
+	return values, nil
+}
+
+// decodeMappings reads the mappings of a version 3 source map. The source, line and column
+// of a segment are relative to the previous segment's, its column to the previous one on
+// the line.
+func (m *sourceMap) decodeMappings() error {
+	source, sourceLine, sourceColumn := 0, 0, 0
+	for _, group := range strings.Split(m.Mappings, ";") {
+		segments := []mappingSegment{}
+		column := 0
+		for _, field := range strings.Split(group, ",") {
+			if field == "" {
+				continue
+			}
+			values, err := decodeVLQ(field)
+			if err != nil {
+				return err
+			}
+			column += values[0]
+			// a segment of generated code only, like a bundler's wrapper
+			if len(values) < 4 {
+				continue
+			}
+			source, sourceLine, sourceColumn = source+values[1], sourceLine+values[2], sourceColumn+values[3]
+			segments = append(segments, mappingSegment{column: column, source: source, sourceLine: sourceLine, sourceColumn: sourceColumn})
+		}
+		m.lines = append(m.lines, segments)
+	}
+	return nil
+}
+
+// original is the source, 1-based line and column a 1-based line and column of the bundle
+// were built from: those of the last segment starting at or before the column.
+func (m *sourceMap) original(line int, col int) (int, int, int, bool) {
+	if line < 1 || line > len(m.lines) {
+		return 0, 0, 0, false
+	}
+	var found *mappingSegment
+	for i, segment := range m.lines[line-1] {
+		if segment.column > col-1 {
+			break
+		}
+		found = &m.lines[line-1][i]
+	}
+	if found == nil || found.source < 0 || found.source >= len(m.Sources) {
+		return 0, 0, 0, false
+	}
+	return found.source, found.sourceLine + 1, found.sourceColumn + 1, true
+}
+
+// parseSourceMap reads a source map, sections of index maps not being supported.
+func parseSourceMap(contents []byte) (*sourceMap, error) {
+	m := &sourceMap{}
+	if err := json.Unmarshal(contents, m); err != nil {
+		return nil, err
+	}
+	if m.Version != 3 {
+		return nil, fmt.Errorf("unsupported version %d", m.Version)
+	}
+	if err := m.decodeMappings(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// loadSourceMap finds the source map of a bundle: inline or next to it as given by its
+// sourceMappingURL comment, or else as bundle.js.map. It also returns the directory the
+// map's sources are relative to, and nil without a map.
+func (s *Scanner) loadSourceMap(filePath string, contents string) (*sourceMap, string, error) {
+	dir := s.fs.Join(filePath, "..")
+	mapPath := filePath + SOURCE_MAP_EXT
+	if found := sourceMappingURL.FindAllStringSubmatch(contents, -1); len(found) > 0 {
+		url := found[len(found)-1][1]
+		if strings.HasPrefix(url, "data:") {
+			header, data, _ := strings.Cut(url, ",")
+			if !strings.HasSuffix(header, ";base64") {
+				return nil, "", fmt.Errorf("unsupported inline source map %q", header)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return nil, "", err
+			}
+			m, err := parseSourceMap(decoded)
+			return m, dir, err
+		}
+		if strings.Contains(url, "://") {
+			return nil, "", nil
+		}
+		mapPath = s.fs.Join(dir, url)
+	}
+	f, err := s.fs.Open(mapPath)
+	if err != nil {
+		// the maps are often left out of a deployed build
+		return nil, "", nil
+	}
+	defer f.Close()
+	decoded, err := io.ReadAll(f)
+	if err != nil {
+		return nil, "", err
+	}
+	m, err := parseSourceMap(decoded)
+	return m, s.fs.Join(mapPath, ".."), err
+}
+
+// bundlePath is the path of a bundle location, path:line:col.
+func bundlePath(bundle string) string {
+	for i := 0; i < 2; i++ {
+		if colon := strings.LastIndex(bundle, ":"); colon >= 0 {
+			bundle = bundle[:colon]
+		}
+	}
+	return bundle
+}
+
+// sourcePath is the path of a source of the map. Bundlers' URLs like
+// webpack://app/./src/App.js are relative to the project, taken to be the root.
+func (s *Scanner) sourcePath(dir string, m *sourceMap, source int) string {
+	sourcePath := m.Sources[source]
+	if scheme, rest, ok := strings.Cut(sourcePath, "://"); ok {
+		if scheme == "file" {
+			return filepath.FromSlash(rest)
+		}
+		_, rel, _ := strings.Cut(rest, "/")
+		return s.fs.Join(s.root, rel)
+	}
+	if strings.HasPrefix(m.SourceRoot+sourcePath, "/") {
+		return m.SourceRoot + sourcePath
+	}
+	return s.fs.Join(dir, m.SourceRoot, sourcePath)
+}
+
+// resolveSourceMap points the matches of a bundle with a source map at the source file,
+// line and column they were built from, keeping the bundle's location in Bundle.
+func (s *Scanner) resolveSourceMap(filePath string, contents string, found []Match) {
+	if filepath.Ext(filePath) != JS_EXT || strings.Contains(filePath, ARCHIVE_SEPARATOR) {
+		return
+	}
+	m, dir, err := s.loadSourceMap(filePath, contents)
+	if err != nil {
+		s.log.Warn().Msg("🗺️ Ignoring the source map of " + filePath + ": " + err.Error())
+		return
+	}
+	if m == nil {
+		return
+	}
+	for i := range found {
+		match := &found[i]
+		source, line, col, ok := m.original(match.Line, match.Col)
+		if !ok {
+			continue
+		}
+		match.Bundle = fmt.Sprintf("%s:%d:%d", match.Path, match.Line, match.Col)
+		match.Path, match.Line, match.Col = s.sourcePath(dir, m, source), line, col
+	}
+}
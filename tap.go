@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tap renders the report in the Test Anything Protocol (version 13): every file with
+// translation content and every unreadable file is a failing test point, its findings
+// in the YAML diagnostic block. A clean tree is a single passing point.
+func (r Report) tap() string {
+	var out strings.Builder
+	out.WriteString("TAP version 13\n")
+	total := len(r.FoundFiles) + len(r.FailedFiles)
+	if total == 0 {
+		out.WriteString("1..1\n")
+		fmt.Fprintf(&out, "ok 1 - %s has no translation content\n", tapDescription(r.Location))
+		return out.String()
+	}
+	fmt.Fprintf(&out, "1..%d\n", total)
+
+	_, byPath := r.issueFiles()
+	point := 0
+	for _, name := range r.FoundFiles {
+		point++
+		fmt.Fprintf(&out, "not ok %d - %s\n", point, tapDescription(workspacePath(name)))
+		findings := byPath[workspacePath(name)]
+		if len(findings) == 0 {
+			continue
+		}
+		out.WriteString("  ---\n  message: translation content\n  findings:\n")
+		for _, finding := range findings {
+			fmt.Fprintf(&out, "    - line: %d\n      col: %d\n      rule: %q\n      snippet: %q\n", finding.Line, finding.Col, finding.Rule, finding.Snippet)
+			if finding.Key != "" {
+				fmt.Fprintf(&out, "      key: %q\n", finding.Key)
+			}
+		}
+		out.WriteString("  ...\n")
+	}
+	for _, failed := range r.FailedFiles {
+		point++
+		fmt.Fprintf(&out, "not ok %d - %s\n", point, tapDescription(workspacePath(failed.Path)))
+		fmt.Fprintf(&out, "  ---\n  message: unreadable file\n  error: %q\n  ...\n", failed.Error)
+	}
+	return out.String()
+}
+
+// tapDescription keeps a description from being read as a directive or a new line.
+func tapDescription(s string) string {
+	return strings.NewReplacer("#", `\#`, "\n", " ", "\r", " ").Replace(s)
+}
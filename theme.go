@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/pterm/pterm"
+)
+
+// Theme sets the colors of the TUI, as hex ("#7D56F4") or ANSI ("205") colors.
+// Name picks a built-in theme, the other fields override its colors.
+type Theme struct {
+	Name               string `yaml:"name"`
+	Banner             string `yaml:"banner"`
+	Spinner            string `yaml:"spinner"`
+	Selected           string `yaml:"selected"`
+	SelectedBackground string `yaml:"selected_background"`
+	Status             string `yaml:"status"`
+	StatusBackground   string `yaml:"status_background"`
+	Error              string `yaml:"error"`
+}
+
+const DEFAULT_THEME = "default"
+
+var BUILTIN_THEMES = map[string]Theme{
+	DEFAULT_THEME: {Spinner: "205", Selected: "170", Status: "230", StatusBackground: "62", Error: "196"},
+	"ocean":       {Banner: "39", Spinner: "45", Selected: "51", Status: "255", StatusBackground: "24", Error: "203"},
+	"forest":      {Banner: "34", Spinner: "78", Selected: "114", Status: "230", StatusBackground: "22", Error: "166"},
+	// black on white or white on black only, for low-vision users and washed-out projectors
+	"high-contrast": {Banner: "15", Spinner: "15", Selected: "0", SelectedBackground: "15", Status: "0", StatusBackground: "15", Error: "15"},
+}
+
+// Styles are the lipgloss styles the TUI renders with.
+type Styles struct {
+	// Banner is nil to keep the terminal's default color.
+	Banner   *pterm.RGB
+	Spinner  lipgloss.Style
+	Selected lipgloss.Style
+	Status   lipgloss.Style
+	Error    lipgloss.Style
+}
+
+var styles = newStyles(BUILTIN_THEMES[DEFAULT_THEME])
+
+// resolveTheme looks up the built-in theme named by the config and applies its overrides.
+func resolveTheme(theme Theme) (Theme, error) {
+	name := theme.Name
+	if name == "" {
+		name = DEFAULT_THEME
+	}
+	resolved, ok := BUILTIN_THEMES[name]
+	if !ok {
+		names := []string{}
+		for n := range BUILTIN_THEMES {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Theme{}, fmt.Errorf("unknown theme %s, expected one of %s", name, strings.Join(names, ", "))
+	}
+	resolved.Name = name
+	for _, override := range []struct{ from, to *string }{
+		{&theme.Banner, &resolved.Banner},
+		{&theme.Spinner, &resolved.Spinner},
+		{&theme.Selected, &resolved.Selected},
+		{&theme.SelectedBackground, &resolved.SelectedBackground},
+		{&theme.Status, &resolved.Status},
+		{&theme.StatusBackground, &resolved.StatusBackground},
+		{&theme.Error, &resolved.Error},
+	} {
+		if *override.from != "" {
+			*override.to = *override.from
+		}
+	}
+	return resolved, nil
+}
+
+func newStyles(theme Theme) Styles {
+	s := Styles{
+		Spinner:  lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Spinner)),
+		Selected: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.Selected)),
+		Status:   lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color(theme.Status)).Background(lipgloss.Color(theme.StatusBackground)),
+		Error:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.Error)),
+	}
+	if theme.SelectedBackground != "" {
+		s.Selected = s.Selected.Background(lipgloss.Color(theme.SelectedBackground))
+	}
+	if theme.Banner != "" {
+		// the banner is drawn by pterm, which wants the color as RGB
+		r, g, b, _ := termenv.ConvertToRGB(termenv.TrueColor.Color(theme.Banner)).RGBA()
+		rgb := pterm.NewRGB(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+		s.Banner = &rgb
+	}
+	return s
+}
+
+// applyTheme sets the TUI styles from the theme section of the config.
+func applyTheme() error {
+	theme, err := resolveTheme(config.Theme)
+	if err != nil {
+		return fmt.Errorf("error in config: %v", err)
+	}
+	styles = newStyles(theme)
+	return nil
+}
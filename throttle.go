@@ -0,0 +1,42 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// NICE_WORKERS is the concurrency of a -nice scan: one file at a time.
+const NICE_WORKERS = 1
+
+// DEFAULT_NICE_READ_RATE is the read throughput of a -nice scan without -read-rate, in bytes per second.
+const DEFAULT_NICE_READ_RATE = 4 << 20
+
+// throttle keeps the reads of a scan under a rate, sleeping whenever they get ahead of it.
+type throttle struct {
+	mu      sync.Mutex
+	rate    int64
+	started time.Time
+	bytes   int64
+}
+
+func newThrottle(rate int) *throttle {
+	if rate <= 0 {
+		return nil
+	}
+	return &throttle{rate: int64(rate), started: time.Now()}
+}
+
+// wait accounts for n bytes read and blocks until the rate allows them.
+func (t *throttle) wait(n int) {
+	if t == nil || n <= 0 {
+		return
+	}
+	t.mu.Lock()
+	t.bytes += int64(n)
+	// whole seconds first, bytes times a second in nanoseconds overflowing past 8 GiB
+	due := t.started.Add(time.Duration(t.bytes/t.rate)*time.Second + time.Duration(t.bytes%t.rate*int64(time.Second)/t.rate))
+	t.mu.Unlock()
+	if delay := time.Until(due); delay > 0 {
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// expired tells whether the scan ran past its -timeout. The first time, the rest of the
+// tree is recorded as a failure of the root.
+func (s *Scanner) expired() bool {
+	if s.deadline.IsZero() || time.Now().Before(s.deadline) {
+		return false
+	}
+	if !s.timedOut.Swap(true) {
+		s.log.Warn().Msg("⏱️ Scan timed out after " + s.options.Timeout.String())
+		s.recordDirFailure(s.root, fmt.Errorf("scan timed out after %s, the rest of the tree was not scanned", s.options.Timeout))
+	}
+	return true
+}
+
+// withTimeout runs read, giving up on it after -file-timeout or when the scan times out,
+// whichever comes first. A read given up on is left to finish in the background: a hung
+// network file system or a FIFO can't be interrupted.
+func (s *Scanner) withTimeout(read func() error) error {
+	timeout := s.options.FileTimeout
+	if !s.deadline.IsZero() {
+		if remaining := time.Until(s.deadline); timeout == 0 || remaining < timeout {
+			timeout = remaining
+		}
+		if timeout <= 0 {
+			timeout = time.Millisecond
+		}
+	}
+	if timeout == 0 {
+		return read()
+	}
+	done := make(chan error, 1)
+	go func() { done <- read() }()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("timed out after %s", timeout.Round(time.Millisecond))
+	}
+}
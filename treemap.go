@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TREEMAP_WIDTH is the width of the map until the terminal size is known.
+const TREEMAP_WIDTH = 80
+
+// DENSITY_LOW and DENSITY_HIGH color the directories without and with only translated files.
+const DENSITY_LOW = "#5C1A1A"
+const DENSITY_HIGH = "#1A5C2A"
+
+// DENSITY_FILLS shade the directories in plain mode, from no to full coverage.
+const DENSITY_FILLS = ".:-=+#"
+
+// treemapCell is the rectangle a directory gets on the map.
+type treemapCell struct {
+	coverage   Coverage
+	x, y, w, h int
+}
+
+// layoutTreemap splits the rectangle between the directories proportionally to their
+// candidate files, halving the list by weight and cutting across the longer side.
+func layoutTreemap(items []Coverage, x int, y int, w int, h int, cells []treemapCell) []treemapCell {
+	if len(items) == 0 || w <= 0 || h <= 0 {
+		return cells
+	}
+	if len(items) == 1 {
+		return append(cells, treemapCell{coverage: items[0], x: x, y: y, w: w, h: h})
+	}
+	total := 0
+	for _, c := range items {
+		total += c.Candidates
+	}
+	split, prefix, best := 1, 0, total
+	for i := 0; i < len(items)-1; i++ {
+		prefix += items[i].Candidates
+		if diff := abs(2*prefix - total); diff < best {
+			split, best = i+1, diff
+		}
+	}
+	first := 0
+	for _, c := range items[:split] {
+		first += c.Candidates
+	}
+	// a character cell is about twice as tall as it is wide
+	if w >= 2*h {
+		cut := clamp(w*first/total, 1, w-1)
+		cells = layoutTreemap(items[:split], x, y, cut, h, cells)
+		return layoutTreemap(items[split:], x+cut, y, w-cut, h, cells)
+	}
+	cut := clamp(h*first/total, 1, h-1)
+	cells = layoutTreemap(items[:split], x, y, w, cut, cells)
+	return layoutTreemap(items[split:], x, y+cut, w, h-cut, cells)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func clamp(n int, low int, high int) int {
+	if high < low {
+		return low
+	}
+	if n < low {
+		return low
+	}
+	if n > high {
+		return high
+	}
+	return n
+}
+
+// densityColor blends from DENSITY_LOW to DENSITY_HIGH with the share of files with translations.
+func densityColor(density float64) lipgloss.Color {
+	var lr, lg, lb, hr, hg, hb int
+	fmt.Sscanf(DENSITY_LOW, "#%02x%02x%02x", &lr, &lg, &lb)
+	fmt.Sscanf(DENSITY_HIGH, "#%02x%02x%02x", &hr, &hg, &hb)
+	mix := func(low int, high int) int { return low + int(float64(high-low)*density) }
+	return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", mix(lr, hr), mix(lg, hg), mix(lb, hb)))
+}
+
+// treemapView draws the top-level directories of the shown scan sized by their candidate
+// files and colored by the share of them with translation content, so untouched corners
+// of a large tree stand out.
+func (m Model) treemapView() string {
+	coverage := append([]Coverage{}, m.history[m.current].Coverage...)
+	if len(coverage) == 0 {
+		return "No candidate files to map."
+	}
+	sort.SliceStable(coverage, func(i, j int) bool { return coverage[i].Candidates > coverage[j].Candidates })
+
+	width, height := m.width, m.listHeight()
+	if width == 0 {
+		width = TREEMAP_WIDTH
+	}
+	cells := layoutTreemap(coverage, 0, 0, width, height, nil)
+
+	// owner holds the cell drawn at each position, -1 for the gaps between cells
+	owner := make([][]int, height)
+	text := make([][]rune, height)
+	for y := range owner {
+		owner[y] = make([]int, width)
+		text[y] = []rune(strings.Repeat(" ", width))
+		for x := range owner[y] {
+			owner[y][x] = -1
+		}
+	}
+	for i, cell := range cells {
+		w, h := cell.w, cell.h
+		if w > 1 {
+			w--
+		}
+		if h > 1 {
+			h--
+		}
+		fill := ' '
+		if plainMode {
+			fill = rune(DENSITY_FILLS[int(cell.coverage.Percent()/100*float64(len(DENSITY_FILLS)-1))])
+		}
+		for y := cell.y; y < cell.y+h; y++ {
+			for x := cell.x; x < cell.x+w; x++ {
+				owner[y][x] = i
+				text[y][x] = fill
+			}
+		}
+		labels := []string{cell.coverage.Directory, fmt.Sprintf("%.0f%% of %d", cell.coverage.Percent(), cell.coverage.Candidates)}
+		for line, label := range labels {
+			if line >= h {
+				break
+			}
+			label := []rune(label)
+			if len(label) > w {
+				label = label[:w]
+			}
+			copy(text[cell.y+line][cell.x:], label)
+		}
+	}
+
+	var out strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; {
+			end := x
+			for end < width && owner[y][end] == owner[y][x] {
+				end++
+			}
+			run := string(text[y][x:end])
+			if i := owner[y][x]; i >= 0 && !plainMode {
+				run = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Background(densityColor(cells[i].coverage.Percent() / 100)).Render(run)
+			}
+			out.WriteString(run)
+			x = end
+		}
+		out.WriteString("\n")
+	}
+	legend := "Sized by candidate files, colored from red (no translations) to green (all translated)."
+	if plainMode {
+		legend = "Sized by candidate files, filled from " + DENSITY_FILLS[:1] + " (no translations) to " + DENSITY_FILLS[len(DENSITY_FILLS)-1:] + " (all translated)."
+	}
+	return out.String() + legend
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const TREND_SPARKLINE = "sparkline"
+const TREND_CSV = "csv"
+
+var SPARK_LEVELS = []rune("▁▂▃▄▅▆▇█")
+
+// historyRoot is the key a location's scans are stored under in the history.
+func historyRoot(location string) string {
+	if isRemote(location) {
+		return location
+	}
+	if abs, err := filepath.Abs(expandHome(location)); err == nil {
+		return abs
+	}
+	return location
+}
+
+// sparkline draws the values as a row of bars scaled between their minimum and maximum.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	low, high := values[0], values[0]
+	for _, v := range values {
+		if v < low {
+			low = v
+		}
+		if v > high {
+			high = v
+		}
+	}
+	spark := []rune{}
+	for _, v := range values {
+		level := 0
+		if high > low {
+			level = int((v - low) / (high - low) * float64(len(SPARK_LEVELS)-1))
+		}
+		if plainMode {
+			spark = append(spark, []rune("_.:-=+*#")[level])
+		} else {
+			spark = append(spark, SPARK_LEVELS[level])
+		}
+	}
+	return string(spark)
+}
+
+// runTrend shows how the matches and coverage of the scanned roots changed over the stored history.
+func runTrend(args []string) {
+	flags := flag.NewFlagSet("trend", flag.ExitOnError)
+	format := flags.String("format", TREND_SPARKLINE, "output format: sparkline or csv")
+	since := flags.Duration("since", 0, "only use the scans of this period, e.g. 720h for the last 30 days (0 for all)")
+	configPath := addConfigFlag(flags)
+	addNoColorFlag(flags)
+	flags.Parse(args)
+
+	if flags.NArg() > 1 || (*format != TREND_SPARKLINE && *format != TREND_CSV) {
+		fmt.Fprintln(os.Stderr, "usage: dirwalker trend [-format sparkline|csv] [-since 720h] [-config file] [directory]")
+		os.Exit(2)
+	}
+	root := ""
+	if flags.NArg() == 1 {
+		root = historyRoot(flags.Arg(0))
+	}
+
+	setupLogger()
+	if err := loadConfig(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if plainMode || noColorRequested() {
+		enablePlainMode()
+	}
+	entries, err := loadHistory(root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *since > 0 {
+		cutoff := time.Now().Add(-*since)
+		recent := []HistoryEntry{}
+		for _, entry := range entries {
+			if entry.ScannedAt.After(cutoff) {
+				recent = append(recent, entry)
+			}
+		}
+		entries = recent
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "no scans in the history, run them with `dirwalker scan -history` or a daemon schedule")
+		os.Exit(1)
+	}
+
+	if *format == TREND_CSV {
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"root", "scanned_at", "files_scanned", "files_found", "matches", "coverage"})
+		for _, entry := range entries {
+			w.Write([]string{
+				entry.Root,
+				entry.ScannedAt.Format(time.RFC3339),
+				strconv.FormatInt(entry.FilesScanned, 10),
+				strconv.Itoa(len(entry.FoundFiles)),
+				strconv.FormatInt(entry.Matches, 10),
+				fmt.Sprintf("%.1f", coveragePercent(entry.Coverage)),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	roots := []string{}
+	byRoot := map[string][]HistoryEntry{}
+	for _, entry := range entries {
+		if _, ok := byRoot[entry.Root]; !ok {
+			roots = append(roots, entry.Root)
+		}
+		byRoot[entry.Root] = append(byRoot[entry.Root], entry)
+	}
+	sortPaths(roots, SORT_NATURAL)
+	for _, r := range roots {
+		scans := byRoot[r]
+		matches, coverage := []float64{}, []float64{}
+		for _, entry := range scans {
+			matches = append(matches, float64(entry.Matches))
+			coverage = append(coverage, coveragePercent(entry.Coverage))
+		}
+		first, last := scans[0], scans[len(scans)-1]
+		fmt.Printf("%s (%d scans, %s to %s)\n", r, len(scans), first.ScannedAt.Format("2006-01-02"), last.ScannedAt.Format("2006-01-02"))
+		fmt.Printf("  matches   %s  %d → %d\n", sparkline(matches), first.Matches, last.Matches)
+		fmt.Printf("  coverage  %s  %.1f%% → %.1f%%\n", sparkline(coverage), coverage[0], coverage[len(coverage)-1])
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const VUE_EXT = ".vue"
+
+// CATALOG_VUE is the format of the messages of an <i18n> block, read but never written.
+const CATALOG_VUE = "vue"
+
+// VUE_T matches vue-i18n's $t and $tc, in templates or as this.$t in a component.
+const VUE_T = `(?:^|[^\w$])\$tc?\(`
+
+// VUE_T_DIRECTIVE matches the v-t directive, v-t="'key'" or v-t="{ path: 'key' }".
+const VUE_T_DIRECTIVE = `\sv-t(?:\.\w+)*=`
+
+var vueT = regexp.MustCompile(VUE_T + `\s*["'` + "`" + `]`)
+var vueTDirective = regexp.MustCompile(VUE_T_DIRECTIVE + `\s*["']`)
+
+// vueI18nBlock matches the custom block of a single file component, at the top level.
+var vueI18nBlock = regexp.MustCompile(`(?m)^<i18n\b`)
+
+// extractVueI18n reads the keys given to $t and $tc and to the v-t directive.
+func extractVueI18n(filePath string, contents string) []ExtractedMessage {
+	messages := []ExtractedMessage{}
+	if !strings.Contains(contents, "$t") && !strings.Contains(contents, "v-t") {
+		return messages
+	}
+	for _, match := range vueT.FindAllStringIndex(contents, -1) {
+		open := strings.IndexByte(contents[match[0]:match[1]], '(') + match[0]
+		if args := callArguments(contents, open); len(args) > 0 {
+			if key, ok := stringLiteral(args[0]); ok && key != "" {
+				messages = append(messages, ExtractedMessage{ID: key, Path: filePath, Line: lineAt(contents, open)})
+			}
+		}
+	}
+	for _, match := range vueTDirective.FindAllStringIndex(contents, -1) {
+		quote := contents[match[1]-1]
+		end := strings.IndexByte(contents[match[1]:], quote)
+		if end < 0 {
+			continue
+		}
+		// the value is an expression: a string literal or an object with a path
+		value := strings.TrimSpace(contents[match[1] : match[1]+end])
+		key, ok := stringLiteral(value)
+		if !ok {
+			key, ok = objectString(value, "path")
+		}
+		if ok && key != "" {
+			messages = append(messages, ExtractedMessage{ID: key, Path: filePath, Line: lineAt(contents, match[1])})
+		}
+	}
+	return messages
+}
+
+// vueCatalogs reads the <i18n> blocks of a single file component as one catalog per locale.
+// A block holds the messages of every locale, { "en": {...}, "fr": {...} }, or of the one
+// of its locale attribute, in JSON or, with lang="yaml", YAML; src loads them from another
+// file. Lines are those of the component.
+func vueCatalogs(filePath string) ([]*Catalog, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading component %s: %v", filePath, err)
+	}
+	text := string(contents)
+	catalogs := []*Catalog{}
+	byLocale := map[string]*Catalog{}
+	for _, match := range vueI18nBlock.FindAllStringIndex(text, -1) {
+		attributes := jsxAttributes(text, match[1])
+		start, selfClosing := tagEnd(text, match[1])
+		end := len(text)
+		if close := strings.Index(text[start:], "</i18n>"); close >= 0 && !selfClosing {
+			end = start + close
+		}
+		block, blockPath, firstLine := []byte(text[start:end]), filePath, lineAt(text, start)
+		if src := attributes["src"]; src != "" {
+			blockPath = filepath.Join(filepath.Dir(filePath), src)
+			if block, err = os.ReadFile(blockPath); err != nil {
+				return nil, fmt.Errorf("error reading catalog %s: %v", blockPath, err)
+			}
+			firstLine = 1
+		}
+		parsed := &Catalog{Path: blockPath, Messages: map[string]Message{}}
+		switch strings.ToLower(attributes["lang"]) {
+		case "", "json":
+			err = parsed.parseJSON(block)
+		case "yaml", "yml":
+			err = parsed.parseYAML(block)
+		default:
+			err = fmt.Errorf("unknown format %s", attributes["lang"])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the <i18n> block of %s: %v", filePath, err)
+		}
+		for _, key := range parsed.Keys {
+			message := parsed.Messages[key]
+			locale := attributes["locale"]
+			if locale == "" {
+				dot := strings.IndexByte(key, '.')
+				if dot < 0 {
+					continue
+				}
+				locale, message.Key = key[:dot], key[dot+1:]
+			}
+			message.Line += firstLine - 1
+			c := byLocale[locale]
+			if c == nil {
+				c = &Catalog{Path: blockPath, Locale: locale, Format: CATALOG_VUE, Messages: map[string]Message{}}
+				byLocale[locale] = c
+				catalogs = append(catalogs, c)
+			}
+			c.add(message)
+		}
+	}
+	return catalogs, nil
+}
+
+// loadComponentCatalogs reads the <i18n> blocks of the single file components found at
+// the given paths, a group of catalogs per component.
+func loadComponentCatalogs(paths []string) ([][]*Catalog, error) {
+	groups := [][]*Catalog{}
+	for _, p := range paths {
+		err := filepath.WalkDir(p, func(filePath string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && (d.Name() == NODE_MODULES_FOLDER || d.Name() == LOGDIRECTORY) {
+				return filepath.SkipDir
+			}
+			if d.IsDir() || filepath.Ext(filePath) != VUE_EXT {
+				return nil
+			}
+			catalogs, err := vueCatalogs(filePath)
+			if err != nil {
+				return err
+			}
+			if len(catalogs) > 0 {
+				groups = append(groups, catalogs)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// catalogName names a catalog in problems: its file, or the locale of a component's block.
+func catalogName(c *Catalog) string {
+	if c.Format == CATALOG_VUE {
+		return "the " + c.Locale + " messages of " + filepath.Base(c.Path)
+	}
+	return filepath.Base(c.Path)
+}
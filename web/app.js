@@ -0,0 +1,152 @@
+"use strict";
+
+let selectedScan = null;
+let selectedDirectory = null;
+let socket = null;
+
+async function fetchJSON(url, options) {
+  const response = await fetch(url, options);
+  const body = await response.json();
+  if (!response.ok) {
+    throw new Error(body.error || response.statusText);
+  }
+  return body;
+}
+
+function cell(row, text) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  row.appendChild(td);
+  return td;
+}
+
+async function loadHistory() {
+  const scans = await fetchJSON("/scans");
+  const tbody = document.getElementById("scans");
+  tbody.innerHTML = "";
+  scans.reverse().forEach((scan) => {
+    const row = document.createElement("tr");
+    if (scan.id === selectedScan) {
+      row.className = "selected";
+    }
+    cell(row, scan.id);
+    cell(row, scan.path);
+    cell(row, scan.status + (scan.error ? ": " + scan.error : ""));
+    cell(row, scan.files_scanned);
+    cell(row, scan.files_matched);
+    cell(row, new Date(scan.started_at).toLocaleString());
+    row.addEventListener("click", () => selectScan(scan));
+    tbody.appendChild(row);
+  });
+}
+
+function renderProgress(event) {
+  const text = event.files_matched + " of " + event.files_scanned + " files found with translation content";
+  document.getElementById("progress").textContent =
+    event.type === "done" ? text + " (" + event.status + ")" : "Please wait while the 🧝 sort .. " + text;
+}
+
+function renderCoverage(coverage) {
+  const tbody = document.getElementById("coverage");
+  tbody.innerHTML = "";
+  coverage.forEach((c) => {
+    const percent = c.candidates === 0 ? 0 : Math.round((c.matched / c.candidates) * 100);
+    const row = document.createElement("tr");
+    if (c.directory === selectedDirectory) {
+      row.className = "selected";
+    }
+    cell(row, c.directory);
+    cell(row, c.candidates);
+    cell(row, c.matched);
+    const bar = document.createElement("span");
+    bar.className = "bar";
+    bar.style.width = percent + "px";
+    const td = cell(row, percent + "%");
+    td.prepend(bar);
+    row.addEventListener("click", () => {
+      selectedDirectory = selectedDirectory === c.directory ? null : c.directory;
+      loadResults(selectedScan);
+    });
+    tbody.appendChild(row);
+  });
+}
+
+// relativeDirectory mirrors the server's coverage grouping: the first path
+// component below the scan root, or "." for files directly in it.
+function relativeDirectory(scanPath, filePath) {
+  const root = scanPath.replace(/^\.\//, "").replace(/\/+$/, "");
+  const rel = root === "." || root === "" ? filePath : filePath.slice(root.length + 1);
+  const slash = rel.indexOf("/");
+  return slash === -1 ? "." : rel.slice(0, slash);
+}
+
+function renderMatches(scanPath, paths) {
+  const list = document.getElementById("matches");
+  list.innerHTML = "";
+  const shown = paths.filter((p) => selectedDirectory === null || relativeDirectory(scanPath, p) === selectedDirectory);
+  document.getElementById("matches-title").textContent =
+    "Matches" + (selectedDirectory ? " in " + selectedDirectory : "") + " (" + shown.length + ")";
+  shown.forEach((p) => {
+    const li = document.createElement("li");
+    li.textContent = p;
+    list.appendChild(li);
+  });
+}
+
+async function loadResults(id) {
+  const results = await fetchJSON("/scans/" + id + "/results");
+  renderCoverage(results.coverage);
+  renderMatches(results.path, results.matched_paths);
+}
+
+function watchScan(scan) {
+  if (socket) {
+    socket.close();
+  }
+  const protocol = location.protocol === "https:" ? "wss:" : "ws:";
+  socket = new WebSocket(protocol + "//" + location.host + "/scans/" + scan.id + "/events");
+  const paths = [];
+  socket.onmessage = (message) => {
+    const event = JSON.parse(message.data);
+    renderProgress(event);
+    if (event.type === "match") {
+      paths.push(event.path);
+      renderMatches(scan.path, paths);
+    }
+    if (event.type === "done") {
+      loadHistory();
+      if (event.status === "done") {
+        loadResults(scan.id);
+      }
+    }
+  };
+}
+
+function selectScan(scan) {
+  selectedScan = scan.id;
+  selectedDirectory = null;
+  document.getElementById("details").hidden = false;
+  document.getElementById("details-title").textContent = "Scan #" + scan.id + " — " + scan.path;
+  document.getElementById("coverage").innerHTML = "";
+  document.getElementById("matches").innerHTML = "";
+  watchScan(scan);
+  loadHistory();
+}
+
+document.getElementById("scan-form").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const error = document.getElementById("form-error");
+  error.textContent = "";
+  try {
+    const scan = await fetchJSON("/scans", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ path: document.getElementById("scan-path").value }),
+    });
+    selectScan(scan);
+  } catch (err) {
+    error.textContent = err.message;
+  }
+});
+
+loadHistory();
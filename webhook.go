@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const WEBHOOK_SLACK = "slack"
+const WEBHOOK_TEAMS = "teams"
+const WEBHOOK_TIMEOUT = 10 * time.Second
+
+// DEFAULT_WEBHOOK_TEMPLATE is used for webhooks without a template of their own.
+const DEFAULT_WEBHOOK_TEMPLATE = `{{.Summary}}
+Coverage {{printf "%.1f" .CoverageBefore}}% → {{printf "%.1f" .CoverageAfter}}%
+{{range .Added}}• new: {{.}}
+{{end}}`
+
+// Webhook is a Slack or Microsoft Teams incoming webhook. Template is a Go text/template
+// rendered with the Notification, e.g. "{{.Root}} has {{len .Added}} new files".
+type Webhook struct {
+	URL      string `yaml:"url"`
+	Format   string `yaml:"format"`
+	Template string `yaml:"template"`
+
+	message *template.Template
+}
+
+// parse checks the webhook's settings and compiles its template. Errors leave the URL
+// out, it usually holds the webhook's secret.
+func (w *Webhook) parse() error {
+	if w.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	switch w.Format {
+	case "":
+		w.Format = WEBHOOK_SLACK
+	case WEBHOOK_SLACK, WEBHOOK_TEAMS:
+	default:
+		return fmt.Errorf("unknown format %q, expected %s or %s", w.Format, WEBHOOK_SLACK, WEBHOOK_TEAMS)
+	}
+	text := w.Template
+	if text == "" {
+		text = DEFAULT_WEBHOOK_TEMPLATE
+	}
+	message, err := template.New("webhook").Parse(text)
+	if err != nil {
+		return fmt.Errorf("error parsing template: %v", err)
+	}
+	w.message = message
+	return nil
+}
+
+// post renders the notification with the template and sends it.
+func (w *Webhook) post(n Notification) error {
+	var text strings.Builder
+	if err := w.message.Execute(&text, n); err != nil {
+		return fmt.Errorf("error rendering webhook message: %v", err)
+	}
+	var payload interface{} = map[string]string{"text": text.String()}
+	if w.Format == WEBHOOK_TEAMS {
+		payload = map[string]string{
+			"@type":    "MessageCard",
+			"@context": "http://schema.org/extensions",
+			"summary":  n.Summary(),
+			"text":     strings.ReplaceAll(text.String(), "\n", "\n\n"),
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding webhook message: %v", err)
+	}
+	client := http.Client{Timeout: WEBHOOK_TIMEOUT}
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// a *url.Error quotes the URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("error posting to webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error posting to webhook: %s", resp.Status)
+	}
+	return nil
+}
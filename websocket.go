@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const EVENT_INTERVAL = 250 * time.Millisecond
+
+const EVENT_PROGRESS = "progress"
+const EVENT_MATCH = "match"
+const EVENT_DONE = "done"
+
+// ScanEvent is pushed to WebSocket clients while a scan runs.
+type ScanEvent struct {
+	Type         string `json:"type"`
+	Path         string `json:"path,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Error        string `json:"error,omitempty"`
+	FilesScanned int64  `json:"files_scanned"`
+	FilesMatched int64  `json:"files_matched"`
+}
+
+var upgrader = websocket.Upgrader{}
+
+// streamEvents upgrades the request to a WebSocket and pushes every match and
+// periodic progress updates until the scan finishes.
+func (srv *Server) streamEvents(w http.ResponseWriter, r *http.Request, job *ScanJob) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already replied to the client
+		logger.Error().Msg(err.Error())
+		return
+	}
+	defer conn.Close()
+
+	// drain incoming frames so close messages from the client are processed
+	go func() {
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(EVENT_INTERVAL)
+	defer ticker.Stop()
+	sent := 0
+	for {
+		srv.mu.Lock()
+		events := []ScanEvent{}
+		scanned, matched := job.scanner.filesScanned.Load(), job.scanner.filesMatched.Load()
+		for _, filePath := range job.matchedPaths[sent:] {
+			events = append(events, ScanEvent{Type: EVENT_MATCH, Path: filePath, FilesScanned: scanned, FilesMatched: matched})
+		}
+		sent = len(job.matchedPaths)
+		if job.Status == SCAN_RUNNING {
+			events = append(events, ScanEvent{Type: EVENT_PROGRESS, FilesScanned: scanned, FilesMatched: matched})
+		} else {
+			events = append(events, ScanEvent{Type: EVENT_DONE, Status: job.Status, Error: job.Error, FilesScanned: scanned, FilesMatched: matched})
+		}
+		srv.mu.Unlock()
+
+		for _, event := range events {
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+		if events[len(events)-1].Type == EVENT_DONE {
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// webHandler serves the embedded dashboard for serve mode.
+func webHandler() http.Handler {
+	files, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		// the directory is embedded at build time, so this can't fail at runtime
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsx renders the report as an Excel workbook with a sheet per section: the summary,
+// the coverage per directory, the files found, their findings and the unreadable files.
+func (r Report) xlsx() (string, error) {
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+
+	header, err := workbook.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"7D56F4"}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error creating workbook: %v", err)
+	}
+	percent, err := workbook.NewStyle(&excelize.Style{NumFmt: 10})
+	if err != nil {
+		return "", fmt.Errorf("error creating workbook: %v", err)
+	}
+
+	summary := [][]interface{}{
+		{"Location", r.Location},
+		{"Scanned at", r.ScannedAt.Format(time.RFC3339)},
+		{"Duration", r.Duration.Round(time.Millisecond).String()},
+		{"Files scanned", r.FilesScanned},
+		{"Files with translation content", len(r.FoundFiles)},
+		{"Matches", r.Matches},
+		{"Unreadable files", len(r.FailedFiles)},
+	}
+	coverage := [][]interface{}{{"Directory", "Files", "With translations", "Coverage"}}
+	for _, c := range r.Coverage {
+		coverage = append(coverage, []interface{}{c.Directory, c.Candidates, c.Matched, c.Percent() / 100})
+	}
+	files := [][]interface{}{{"File", "Rules"}}
+	for _, file := range r.FilesWithRules() {
+		files = append(files, []interface{}{file.Path, file.Rules})
+	}
+	findings := [][]interface{}{{"File", "Line", "Column", "Rule", "Key", "Snippet"}}
+	for _, finding := range r.Findings {
+		findings = append(findings, []interface{}{finding.Path, finding.Line, finding.Col, finding.Rule, finding.Key, finding.Snippet})
+	}
+	failed := [][]interface{}{{"File", "Error"}}
+	for _, f := range r.FailedFiles {
+		failed = append(failed, []interface{}{f.Path, f.Error})
+	}
+
+	sheets := []struct {
+		name   string
+		rows   [][]interface{}
+		widths []float64
+	}{
+		{"Summary", summary, []float64{32, 60}},
+		{"Coverage", coverage, []float64{50, 10, 18, 12}},
+		{"Files", files, []float64{90, 40}},
+		{"Findings", findings, []float64{90, 8, 8, 30, 30, 80}},
+		{"Unreadable", failed, []float64{90, 60}},
+	}
+	for i, sheet := range sheets {
+		if i == 0 {
+			if err := workbook.SetSheetName("Sheet1", sheet.name); err != nil {
+				return "", fmt.Errorf("error creating workbook: %v", err)
+			}
+		} else if _, err := workbook.NewSheet(sheet.name); err != nil {
+			return "", fmt.Errorf("error creating workbook: %v", err)
+		}
+		for row, values := range sheet.rows {
+			cell, _ := excelize.CoordinatesToCellName(1, row+1)
+			if err := workbook.SetSheetRow(sheet.name, cell, &values); err != nil {
+				return "", fmt.Errorf("error writing sheet %s: %v", sheet.name, err)
+			}
+		}
+		for column, width := range sheet.widths {
+			name, _ := excelize.ColumnNumberToName(column + 1)
+			workbook.SetColWidth(sheet.name, name, name, width)
+		}
+		if sheet.name == "Summary" {
+			workbook.SetCellStyle(sheet.name, "A1", fmt.Sprintf("A%d", len(sheet.rows)), header)
+			continue
+		}
+		// the header row stays visible while scrolling and filters the columns
+		last, _ := excelize.CoordinatesToCellName(len(sheet.rows[0]), 1)
+		workbook.SetCellStyle(sheet.name, "A1", last, header)
+		workbook.SetPanes(sheet.name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+		if len(sheet.rows) > 1 {
+			end, _ := excelize.CoordinatesToCellName(len(sheet.rows[0]), len(sheet.rows))
+			workbook.AutoFilter(sheet.name, "A1:"+end, nil)
+		}
+	}
+	if len(coverage) > 1 {
+		workbook.SetCellStyle("Coverage", "D2", fmt.Sprintf("D%d", len(coverage)), percent)
+	}
+
+	var out bytes.Buffer
+	if err := workbook.Write(&out); err != nil {
+		return "", fmt.Errorf("error writing workbook: %v", err)
+	}
+	return out.String(), nil
+}